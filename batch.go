@@ -0,0 +1,189 @@
+package magic
+
+/*
+#include "functions.h"
+*/
+import "C"
+
+import (
+	"unsafe"
+)
+
+// FileBatch returns a structured result for each of the named files,
+// as per FileResult, alongside an error for each of the named files,
+// which is nil when the file was successfully identified.
+//
+// The lock is taken and the flags are set only once for the whole
+// batch, and the Magic library is then queried for all of the named
+// files at once for each kind of result, which avoids the overhead
+// of calling FileResult for each of the named files separately.
+func (mgc *Magic) FileBatch(files []string) ([]Result, []error) {
+	cFiles := make([]*C.char, len(files))
+	for i, file := range files {
+		cFiles[i] = C.CString(file)
+	}
+	defer func() {
+		for _, cFile := range cFiles {
+			C.free(unsafe.Pointer(cFile))
+		}
+	}()
+
+	var p **C.char
+	if len(cFiles) > 0 {
+		p = &cFiles[0]
+	}
+
+	return mgc.resultBatch(len(files), func(batch *C.batch_t, flags, output C.int) {
+		C.magic_file_batch_wrapper(mgc.cookie, p, C.size_t(len(cFiles)), batch, flags, output)
+	})
+}
+
+// BufferBatch returns a structured result for the content of each
+// of the buffers, as per BufferResult and FileBatch.
+//
+// The content of the buffers is copied for the duration of the call.
+func (mgc *Magic) BufferBatch(buffers [][]byte) ([]Result, []error) {
+	cSize := C.size_t(len(buffers))
+	cPointers := make([]unsafe.Pointer, cSize)
+	cSizes := make([]C.size_t, cSize)
+
+	// The Magic library is given the copies in C memory, as the
+	// pointers to Go memory cannot be passed within an array.
+	defer func() {
+		for _, p := range cPointers {
+			C.free(p)
+		}
+	}()
+	for i, buffer := range buffers {
+		if len(buffer) > 0 {
+			cPointers[i] = C.CBytes(buffer)
+			cSizes[i] = C.size_t(len(buffer))
+		}
+	}
+
+	var (
+		p *unsafe.Pointer
+		s *C.size_t
+	)
+	if cSize > 0 {
+		p = &cPointers[0]
+		s = &cSizes[0]
+	}
+
+	return mgc.resultBatch(len(buffers), func(batch *C.batch_t, flags, output C.int) {
+		C.magic_buffer_batch_wrapper(mgc.cookie, p, s, cSize, batch, flags, output)
+	})
+}
+
+// resultBatch assembles structured results for a batch of n items by
+// calling the function once for each kind of result with the appropriate
+// flags set, as per the result function.
+func (mgc *Magic) resultBatch(n int, f func(*C.batch_t, C.int, C.int)) ([]Result, []error) {
+	mgc.Lock()
+	defer mgc.Unlock()
+
+	if err := verifyOpen(mgc); err != nil {
+		return failBatch(n, err)
+	}
+	if err := verifyLoaded(mgc); err != nil {
+		return failBatch(n, err)
+	}
+
+	results := make([]Result, n)
+	errs := make([]error, n)
+	if n == 0 {
+		return results, errs
+	}
+
+	flags := resultQueryFlags(mgc)
+	defer C.magic_setflags_wrapper(mgc.cookie, C.int(mgc.flags))
+
+	batch := make([]C.batch_t, n)
+
+	query := func(flags Flags, i int) (string, error) {
+		b := &batch[i]
+		defer C.free(unsafe.Pointer(b.result))
+		defer C.free(unsafe.Pointer(b.error))
+
+		return resultString(flags, b.result, b.error, func() error {
+			return batchError(b)
+		})
+	}
+
+	for _, kind := range resultKinds {
+		C.magic_setflags_wrapper(mgc.cookie, C.int(flags|kind.flag))
+
+		mgc.diagnose(func(output C.int) {
+			f(&batch[0], C.int(flags|kind.flag), output)
+		})
+
+		for i := range batch {
+			s, err := query(flags|kind.flag, i)
+			if errs[i] != nil {
+				continue
+			}
+			if err == nil {
+				err = kind.set(&results[i], s)
+			}
+			if err != nil && kind.fatal {
+				errs[i] = err
+			}
+		}
+	}
+
+	// Results of the items that failed are not meaningful.
+	for i := range results {
+		if errs[i] != nil {
+			results[i] = Result{}
+		}
+	}
+	return results, errs
+}
+
+// failBatch returns empty results for a batch of n items, each
+// alongside the same error.
+func failBatch(n int, err error) ([]Result, []error) {
+	errs := make([]error, n)
+	for i := range errs {
+		errs[i] = err
+	}
+	return make([]Result, n), errs
+}
+
+// batchError returns the error reported for a single item of a batch.
+func batchError(b *C.batch_t) error {
+	if b.error == nil {
+		return &Error{-1, "an unknown error has occurred"}
+	}
+	s := C.GoString(b.error)
+	if s == "" || s == "(null)" {
+		return &Error{-1, "empty or invalid error message"}
+	}
+	return &Error{int(b.error_number), s}
+}
+
+// FileBatch returns a structured result for each of the named files,
+// as per the FileBatch function of the Magic type.
+func (p *Pool) FileBatch(files []string) (results []Result, errs []error) {
+	err := p.do(func(mgc *Magic) error {
+		results, errs = mgc.FileBatch(files)
+		return nil
+	})
+	if err != nil {
+		return failBatch(len(files), err)
+	}
+	return
+}
+
+// BufferBatch returns a structured result for the content of each
+// of the buffers, as per the BufferBatch function of the Magic type.
+func (p *Pool) BufferBatch(buffers [][]byte) (results []Result, errs []error) {
+	err := p.do(func(mgc *Magic) error {
+		results, errs = mgc.BufferBatch(buffers)
+		return nil
+	})
+	if err != nil {
+		return failBatch(len(buffers), err)
+	}
+	return
+}
@@ -0,0 +1,236 @@
+package magic
+
+import (
+	"io/ioutil"
+	"path"
+	"reflect"
+	"testing"
+)
+
+func TestMagic_FileBatch(t *testing.T) {
+	mgc, err := New(WithFlags(MIME_TYPE))
+	if err != nil {
+		t.Fatalf("unable to create new Magic type: %s", err.Error())
+	}
+	defer mgc.Close()
+
+	files := []string{
+		sampleImageFile,
+		"does/not/exist",
+		path.Join(fixturesDirectory, "gopher.jpg"),
+	}
+
+	results, errs := mgc.FileBatch(files)
+	if len(results) != len(files) || len(errs) != len(files) {
+		t.Fatalf("value given %d %d, want %d", len(results), len(errs), len(files))
+	}
+
+	for _, i := range []int{0, 2} {
+		if errs[i] != nil {
+			t.Errorf("value given {%v}, want {%v}", errs[i], nil)
+			continue
+		}
+		rv, err := mgc.FileResult(files[i])
+		if err != nil {
+			t.Fatalf("value given {%v}, want {%v}", err, nil)
+		}
+		if !reflect.DeepEqual(results[i], rv) {
+			t.Errorf("value given %+v, want %+v", results[i], rv)
+		}
+	}
+
+	if errs[1] == nil {
+		t.Errorf("value given {%v}, want an error", errs[1])
+	}
+	if !reflect.DeepEqual(results[1], Result{}) {
+		t.Errorf("value given %+v, want %+v", results[1], Result{})
+	}
+
+	// Flags currently set should remain unchanged.
+	if flags, _ := mgc.Flags(); flags != MIME_TYPE {
+		t.Errorf("value given 0x%06x, want 0x%06x", flags, MIME_TYPE)
+	}
+
+	results, errs = mgc.FileBatch(nil)
+	if len(results) != 0 || len(errs) != 0 {
+		t.Errorf("value given %d %d, want %d", len(results), len(errs), 0)
+	}
+}
+
+func TestMagic_BufferBatch(t *testing.T) {
+	mgc, err := New()
+	if err != nil {
+		t.Fatalf("unable to create new Magic type: %s", err.Error())
+	}
+	defer mgc.Close()
+
+	image, err := ioutil.ReadFile(sampleImageFile)
+	if err != nil {
+		t.Fatalf("unable to read file %q: %s", sampleImageFile, err.Error())
+	}
+
+	buffers := [][]byte{
+		image,
+		[]byte("#!/bin/bash\n\n"),
+		{},
+	}
+
+	results, errs := mgc.BufferBatch(buffers)
+	if len(results) != len(buffers) || len(errs) != len(buffers) {
+		t.Fatalf("value given %d %d, want %d", len(results), len(errs), len(buffers))
+	}
+
+	for i, buffer := range buffers {
+		rv, err := mgc.BufferResult(buffer)
+		if !reflect.DeepEqual(errs[i], err) {
+			t.Errorf("value given {%v}, want {%v}", errs[i], err)
+		}
+		if !reflect.DeepEqual(results[i], rv) {
+			t.Errorf("value given %+v, want %+v", results[i], rv)
+		}
+	}
+
+	if v := "image/png"; !compareStrings(results[0].MIMEType, v) {
+		t.Errorf("value given %q, want %q", results[0].MIMEType, v)
+	}
+	if v := "text/x-shellscript"; !compareStrings(results[1].MIMEType, v) {
+		t.Errorf("value given %q, want %q", results[1].MIMEType, v)
+	}
+}
+
+func TestMagic_BufferBatch_BytesMax(t *testing.T) {
+	mgc, err := New()
+	if err != nil {
+		t.Fatalf("unable to create new Magic type: %s", err.Error())
+	}
+	defer mgc.Close()
+
+	if err := mgc.SetParameter(PARAM_BYTES_MAX, 8); err != nil {
+		t.Fatalf("unable to set parameter: %s", err.Error())
+	}
+
+	// The whole of each buffer has to be given to the Magic library,
+	// which looks beyond the limit set for text content.
+	buffers := [][]byte{[]byte("#!/bin/bash\n\necho 'Hello, World!'\n")}
+
+	results, errs := mgc.BufferBatch(buffers)
+	rv, err := mgc.BufferResult(buffers[0])
+	if !reflect.DeepEqual(errs[0], err) {
+		t.Errorf("value given {%v}, want {%v}", errs[0], err)
+	}
+	if !reflect.DeepEqual(results[0], rv) {
+		t.Errorf("value given %+v, want %+v", results[0], rv)
+	}
+}
+
+func TestMagic_BufferBatch_Closed(t *testing.T) {
+	mgc, err := New()
+	if err != nil {
+		t.Fatalf("unable to create new Magic type: %s", err.Error())
+	}
+	mgc.Close()
+
+	_, errs := mgc.BufferBatch([][]byte{{0}, {1}})
+	for _, err := range errs {
+		if err != ErrNotOpen {
+			t.Errorf("value given {%v}, want {%v}", err, ErrNotOpen)
+		}
+	}
+}
+
+func TestPool_FileBatch(t *testing.T) {
+	p, err := NewPool(2)
+	if err != nil {
+		t.Fatalf("unable to create new Pool type: %s", err.Error())
+	}
+	defer p.Close()
+
+	results, errs := p.FileBatch([]string{sampleImageFile})
+	if errs[0] != nil {
+		t.Fatalf("value given {%v}, want {%v}", errs[0], nil)
+	}
+	if v := "image/png"; !compareStrings(results[0].MIMEType, v) {
+		t.Errorf("value given %q, want %q", results[0].MIMEType, v)
+	}
+
+	p.Close()
+
+	_, errs = p.BufferBatch([][]byte{{0}})
+	if errs[0] != ErrNotOpen {
+		t.Errorf("value given {%v}, want {%v}", errs[0], ErrNotOpen)
+	}
+}
+
+// batchSize is the number of items identified in each iteration
+// of the batch benchmarks and their per-item counterparts.
+const batchSize = 64
+
+func benchmarkBuffers(b *testing.B) [][]byte {
+	buffer, err := ioutil.ReadFile(sampleImageFile)
+	if err != nil {
+		b.Fatalf("unable to read file %q: %s", sampleImageFile, err.Error())
+	}
+	buffers := make([][]byte, batchSize)
+	for i := range buffers {
+		buffers[i] = buffer[:1024]
+	}
+	return buffers
+}
+
+func benchmarkFiles() []string {
+	files := make([]string, batchSize)
+	for i := range files {
+		files[i] = sampleImageFile
+	}
+	return files
+}
+
+func BenchmarkMagic_BufferResult(b *testing.B) {
+	buffers := benchmarkBuffers(b)
+	benchmarkMagic(b, MIME_TYPE, func(mgc *Magic) error {
+		for _, buffer := range buffers {
+			if _, err := mgc.BufferResult(buffer); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func BenchmarkMagic_BufferBatch(b *testing.B) {
+	buffers := benchmarkBuffers(b)
+	benchmarkMagic(b, MIME_TYPE, func(mgc *Magic) error {
+		_, errs := mgc.BufferBatch(buffers)
+		for _, err := range errs {
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func BenchmarkMagic_FileResult(b *testing.B) {
+	files := benchmarkFiles()
+	benchmarkMagic(b, MIME_TYPE, func(mgc *Magic) error {
+		for _, file := range files {
+			if _, err := mgc.FileResult(file); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func BenchmarkMagic_FileBatch(b *testing.B) {
+	files := benchmarkFiles()
+	benchmarkMagic(b, MIME_TYPE, func(mgc *Magic) error {
+		_, errs := mgc.FileBatch(files)
+		for _, err := range errs {
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
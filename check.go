@@ -0,0 +1,188 @@
+package magic
+
+/*
+#include "functions.h"
+*/
+import "C"
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"unsafe"
+)
+
+// Severity represents the severity of a diagnostic.
+type Severity int
+
+const (
+	// A warning that does not prevent the Magic database
+	// file from being used.
+	SeverityWarning Severity = iota
+
+	// An error that prevents the Magic database file from
+	// being used.
+	SeverityError
+)
+
+// String returns a string representation of the Severity type.
+func (s Severity) String() string {
+	switch s {
+	case SeverityWarning:
+		return "warning"
+	case SeverityError:
+		return "error"
+	}
+	return "Severity(" + strconv.Itoa(int(s)) + ")"
+}
+
+// CheckDiagnostic represents a single diagnostic, either a warning
+// or an error, reported when checking the Magic database file.
+type CheckDiagnostic struct {
+	File     string   // The Magic database file, if known.
+	Line     int      // The line number, if known, or 0 otherwise.
+	Severity Severity // The severity of the diagnostic.
+	Message  string   // The actual message.
+}
+
+// String returns a string representation of the CheckDiagnostic type
+// in the "file:line: severity: message" format.
+func (d CheckDiagnostic) String() string {
+	var position string
+	switch {
+	case d.File != "" && d.Line > 0:
+		position = fmt.Sprintf("%s:%d: ", d.File, d.Line)
+	case d.File != "":
+		position = d.File + ": "
+	}
+	return fmt.Sprintf("%s%s: %s", position, d.Severity, d.Message)
+}
+
+var (
+	// Warnings are reported as "<file>, <line>: Warning: <message>",
+	// or as "Warning: <message>" when not specific to any file.
+	warningRegexp = regexp.MustCompile(`^(?:(.+), (\d+): )?Warning: (.*)$`)
+	// Errors are reported as "line <line>: <message>".
+	errorRegexp = regexp.MustCompile(`^line (\d+):\s*(.*)$`)
+)
+
+// CheckDiagnostics checks the Magic database file for consistency,
+// as per Check, and returns the diagnostics that the Magic library
+// reported, with the CHECK flag set, such as warnings about entries
+// that are not valid, alongside the error, if any.
+//
+// The diagnostics include the error, should the Magic database file
+// fail the check, which is then also returned.
+//
+// The Magic library frees the Magic database currently loaded when it
+// checks, thus the file is checked using a private Magic library session,
+// leaving the Magic database in use intact.
+func (mgc *Magic) CheckDiagnostics(file string) ([]CheckDiagnostic, error) {
+	mgc.RLock()
+	defer mgc.RUnlock()
+
+	if err := verifyOpen(mgc); err != nil {
+		return nil, err
+	}
+
+	session, err := open()
+	if err != nil {
+		return nil, err
+	}
+	defer session.close()
+
+	var (
+		cRv    C.int
+		buffer bytes.Buffer
+	)
+
+	cFile := C.CString(file)
+	defer C.free(unsafe.Pointer(cFile))
+
+	mgc.outputMutex.Lock()
+	defer mgc.outputMutex.Unlock()
+
+	// Capture the diagnostic output for the purpose of the
+	// check, while still delivering it to the writer set.
+	output, w := mgc.output, io.Writer(&buffer)
+	if output != nil {
+		w = io.MultiWriter(&buffer, mgc.diagnostics)
+	} else {
+		f, err := newOutput()
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+		output = f
+	}
+
+	capture(output, w, func(output C.int) {
+		cRv = C.magic_check_wrapper(session.cookie, cFile, C.int(mgc.flags|CHECK), output)
+	})
+
+	diagnostics := parseCheckDiagnostics(&buffer)
+	if cRv < 0 {
+		err := session.error()
+		if e, ok := err.(*Error); ok {
+			diagnostics = append(diagnostics, parseCheckError(file, e.Message))
+		}
+		return diagnostics, err
+	}
+	return diagnostics, nil
+}
+
+// CheckDiagnostics checks the Magic database file for consistency
+// and returns the diagnostics, as per the CheckDiagnostics function
+// of the Magic type.
+func CheckDiagnostics(file string) ([]CheckDiagnostic, error) {
+	mgc, err := open()
+	if err != nil {
+		return nil, err
+	}
+	defer mgc.close()
+	return mgc.CheckDiagnostics(file)
+}
+
+// parseCheckDiagnostics parses the warnings out of the diagnostic
+// output of the Magic library.
+func parseCheckDiagnostics(r io.Reader) []CheckDiagnostic {
+	var diagnostics []CheckDiagnostic
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		m := warningRegexp.FindStringSubmatch(scanner.Text())
+		if m == nil {
+			// Skip anything else e.g., listing of the entries
+			// that the Magic library prints when checking.
+			continue
+		}
+		// Skip the notice about the Magic database file not
+		// being compiled, which is always the case here.
+		if strings.HasPrefix(m[3], "using regular magic file") {
+			continue
+		}
+		line, _ := strconv.Atoi(m[2])
+		diagnostics = append(diagnostics, CheckDiagnostic{
+			File:     m[1],
+			Line:     line,
+			Severity: SeverityWarning,
+			Message:  m[3],
+		})
+	}
+	return diagnostics
+}
+
+// parseCheckError parses the error message reported by the Magic
+// library, which would not include the name of the file.
+func parseCheckError(file, s string) CheckDiagnostic {
+	d := CheckDiagnostic{File: file, Severity: SeverityError, Message: s}
+	if m := errorRegexp.FindStringSubmatch(s); m != nil {
+		d.Line, _ = strconv.Atoi(m[1])
+		d.Message = m[2]
+	}
+	return d
+}
@@ -0,0 +1,79 @@
+package magic
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestCheckDiagnostics(t *testing.T) {
+	var checkTests = []struct {
+		file        string
+		diagnostics []CheckDiagnostic
+		err         bool
+	}{
+		{
+			shellMagicFile,
+			nil,
+			false,
+		},
+		{
+			warningMagicFile,
+			[]CheckDiagnostic{
+				{warningMagicFile, 3, SeverityWarning, "New continuation level 2 is more than one larger than current level 0"},
+			},
+			false,
+		},
+		{
+			brokenMagicFile,
+			[]CheckDiagnostic{
+				{brokenMagicFile, 1, SeverityError, "No current entry for continuation"},
+			},
+			true,
+		},
+	}
+
+	for _, tt := range checkTests {
+		diagnostics, err := CheckDiagnostics(tt.file)
+		if (err != nil) != tt.err {
+			t.Errorf("value given {%v} for %q, want error %t", err, tt.file, tt.err)
+		}
+		if !reflect.DeepEqual(diagnostics, tt.diagnostics) {
+			t.Errorf("value given %q, want %q", diagnostics, tt.diagnostics)
+		}
+	}
+}
+
+func TestMagic_CheckDiagnostics_Loaded(t *testing.T) {
+	mgc, err := New(WithFlags(MIME_TYPE))
+	if err != nil {
+		t.Fatalf("unable to create new Magic type: %s", err.Error())
+	}
+	defer mgc.Close()
+
+	rv, _ := mgc.File(sampleImageFile)
+	if v := "image/png"; !compareStrings(rv, v) {
+		t.Fatalf("value given %q, want %q", rv, v)
+	}
+
+	for _, file := range []string{shellMagicFile, brokenMagicFile} {
+		mgc.CheckDiagnostics(file)
+	}
+
+	// The Magic database loaded has to remain in use.
+	rv, _ = mgc.File(sampleImageFile)
+	if v := "image/png"; !compareStrings(rv, v) {
+		t.Errorf("value given %q, want %q", rv, v)
+	}
+	if !mgc.HasLoaded() {
+		t.Errorf("value given %v, want %v", false, true)
+	}
+}
+
+func TestCheckDiagnostic_String(t *testing.T) {
+	d := CheckDiagnostic{"png.magic", 3, SeverityWarning, "the quick brown fox"}
+
+	v := "png.magic:3: warning: the quick brown fox"
+	if ok := compareStrings(d.String(), v); !ok {
+		t.Errorf("value given %q, want %q", d.String(), v)
+	}
+}
@@ -0,0 +1,396 @@
+// Command gomagic determines the type of files, mirroring the common
+// options of the file(1) command, using the Magic library as the magic
+// package does.
+//
+// Usage:
+//
+//	gomagic [flags] file ...
+//
+// Short flags can be combined, e.g., "-bi", and the "-" file denotes
+// the standard input. Run "gomagic -help" for the list of flags.
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/kwilczynski/go-magic"
+)
+
+// Names of the tests that can be excluded using the "-e" flag.
+var tests = map[string]int{
+	"apptype":  magic.NO_CHECK_APPTYPE,
+	"ascii":    magic.NO_CHECK_TEXT,
+	"cdf":      magic.NO_CHECK_CDF,
+	"compress": magic.NO_CHECK_COMPRESS,
+	"csv":      magic.NO_CHECK_CSV,
+	"elf":      magic.NO_CHECK_ELF,
+	"encoding": magic.NO_CHECK_ENCODING,
+	"json":     magic.NO_CHECK_JSON,
+	"soft":     magic.NO_CHECK_SOFT,
+	"tar":      magic.NO_CHECK_TAR,
+	"text":     magic.NO_CHECK_TEXT,
+	"tokens":   magic.NO_CHECK_TOKENS,
+}
+
+// Names of the parameters that can be set using the "-P" flag.
+var parameters = map[string]int{
+	"bytes":     magic.PARAM_BYTES_MAX,
+	"elf_notes": magic.PARAM_ELF_NOTES_MAX,
+	"elf_phnum": magic.PARAM_ELF_PHNUM_MAX,
+	"elf_shnum": magic.PARAM_ELF_SHNUM_MAX,
+	"indir":     magic.PARAM_INDIR_MAX,
+	"name":      magic.PARAM_NAME_MAX,
+	"regex":     magic.PARAM_REGEX_MAX,
+}
+
+// Short flags that take a value, which can follow the flag immediately
+// when combined with other short flags, e.g., "-bm magic.mgc".
+const valueFlags = "efmFP"
+
+// config represents the configuration set using the flags.
+type config struct {
+	flags      int
+	parameters [][2]int
+	brief      bool
+	noPad      bool
+	print0     bool
+	errors     bool
+	version    bool
+	separator  string
+	magicFiles string
+	nameFile   string
+	json       bool
+	ndjson     bool
+}
+
+// flagFunc is a boolean flag that calls the function when set.
+type flagFunc func()
+
+func (f flagFunc) IsBoolFlag() bool { return true }
+func (f flagFunc) String() string   { return "" }
+func (f flagFunc) Set(s string) error {
+	if v, err := strconv.ParseBool(s); err != nil || !v {
+		return err
+	}
+	f()
+	return nil
+}
+
+// valueFunc is a flag that calls the function with each value given.
+type valueFunc func(string) error
+
+func (f valueFunc) String() string     { return "" }
+func (f valueFunc) Set(s string) error { return f(s) }
+
+// newFlagSet returns the set of flags that updates the configuration.
+func newFlagSet(c *config) *flag.FlagSet {
+	fs := flag.NewFlagSet("gomagic", flag.ContinueOnError)
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "usage: gomagic [flags] file ...\n")
+		fs.PrintDefaults()
+	}
+
+	set := func(flags int) flagFunc {
+		return func() { c.flags |= flags }
+	}
+	both := func(value flag.Value, short, long, usage string) {
+		fs.Var(value, short, usage)
+		// Keep the name of the value, if any, e.g., "-m list".
+		name, _ := flag.UnquoteUsage(fs.Lookup(short))
+		if _, ok := value.(flagFunc); ok || name == "" {
+			fs.Var(value, long, "same as -"+short)
+			return
+		}
+		fs.Var(value, long, "same as -"+short+" `"+name+"`")
+	}
+
+	both(flagFunc(func() { c.brief = true }), "b", "brief", "do not prepend file names to results")
+	both(set(magic.MIME), "i", "mime", "output MIME type and encoding")
+	fs.Var(set(magic.MIME_TYPE), "mime-type", "output MIME type only")
+	fs.Var(set(magic.MIME_ENCODING), "mime-encoding", "output MIME encoding only")
+	fs.Var(set(magic.EXTENSION), "extension", "output file extensions")
+	fs.Var(set(magic.APPLE), "apple", "output Apple creator and type")
+	both(set(magic.CONTINUE), "k", "keep-going", "do not stop at the first match")
+	both(set(magic.SYMLINK), "L", "dereference", "follow symbolic links")
+	both(flagFunc(func() { c.flags &^= magic.SYMLINK }), "h", "no-dereference", "do not follow symbolic links")
+	both(set(magic.COMPRESS), "z", "uncompress", "look inside compressed files")
+	both(set(magic.COMPRESS|magic.COMPRESS_TRANSP), "Z", "uncompress-noreport", "only report the contents of compressed files")
+	both(set(magic.DEVICES), "s", "special-files", "read block and character device files")
+	both(set(magic.RAW), "r", "raw", "do not translate unprintable characters")
+	both(set(magic.PRESERVE_ATIME), "p", "preserve-date", "preserve access time of files")
+	both(set(magic.DEBUG), "d", "debug", "print debugging messages")
+	both(flagFunc(func() { c.noPad = true }), "N", "no-pad", "do not pad file names to align results")
+	both(flagFunc(func() { c.print0 = true }), "0", "print0", "output a NUL character after file names")
+	fs.Var(flagFunc(func() { c.errors = true }), "E", "report any errors, rather than results, and exit")
+	both(flagFunc(func() { c.version = true }), "v", "version", "output version information and exit")
+	fs.BoolVar(&c.json, "json", false, "output the results as a JSON array")
+	fs.BoolVar(&c.ndjson, "ndjson", false, "output the results as JSON, one object per line")
+
+	both(valueFunc(func(s string) error {
+		flags, ok := tests[s]
+		if !ok {
+			return fmt.Errorf("unknown test name %q", s)
+		}
+		c.flags |= flags
+		return nil
+	}), "e", "exclude", "exclude the test `name` from the list of tests")
+	both(valueFunc(func(s string) error {
+		kv := strings.SplitN(s, "=", 2)
+		parameter, ok := parameters[kv[0]]
+		if len(kv) != 2 || !ok {
+			return fmt.Errorf("unknown parameter %q", s)
+		}
+		value, err := strconv.Atoi(kv[1])
+		if err != nil {
+			return fmt.Errorf("invalid parameter value %q", s)
+		}
+		c.parameters = append(c.parameters, [2]int{parameter, value})
+		return nil
+	}), "P", "parameter", "set the parameter `name=value`")
+	both(valueFunc(func(s string) error {
+		c.magicFiles = s
+		return nil
+	}), "m", "magic-file", "colon-separated `list` of Magic database files")
+	both(valueFunc(func(s string) error {
+		c.nameFile = s
+		return nil
+	}), "f", "files-from", "read the names of files from the `file`, or \"-\"")
+	both(valueFunc(func(s string) error {
+		c.separator = s
+		return nil
+	}), "F", "separator", "use the `string` as the separator after file names")
+
+	return fs
+}
+
+// expandArgs expands any combined short flags, e.g., "-bi" into "-b"
+// and "-i", and "-bmmagic.mgc" into "-b", "-m" and "magic.mgc".
+func expandArgs(fs *flag.FlagSet, args []string) []string {
+	var expanded []string
+
+	for i, arg := range args {
+		if arg == "--" {
+			return append(expanded, args[i:]...)
+		}
+		name := strings.SplitN(strings.TrimPrefix(arg, "-"), "=", 2)[0]
+		if len(arg) <= 2 || arg[0] != '-' || arg[1] == '-' || fs.Lookup(name) != nil {
+			expanded = append(expanded, arg)
+			continue
+		}
+		for j := 1; j < len(arg); j++ {
+			expanded = append(expanded, "-"+arg[j:j+1])
+			if strings.IndexByte(valueFlags, arg[j]) >= 0 {
+				if j+1 < len(arg) {
+					expanded = append(expanded, arg[j+1:])
+				}
+				break
+			}
+		}
+	}
+	return expanded
+}
+
+// readNames reads the names of files, one per line, from the file.
+func readNames(file string) ([]string, error) {
+	var r io.Reader = os.Stdin
+	if file != "-" {
+		f, err := os.Open(file)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+		r = f
+	}
+
+	var names []string
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		if name := scanner.Text(); name != "" {
+			names = append(names, name)
+		}
+	}
+	return names, scanner.Err()
+}
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdout, os.Stderr))
+}
+
+func run(args []string, stdout, stderr io.Writer) int {
+	c := &config{separator: ":"}
+
+	fs := newFlagSet(c)
+	fs.SetOutput(stderr)
+	if err := fs.Parse(expandArgs(fs, args)); err != nil {
+		if err == flag.ErrHelp {
+			return 0
+		}
+		return 2
+	}
+
+	if c.version {
+		fmt.Fprintf(stdout, "gomagic (libmagic %s)\n", magic.VersionString())
+		return 0
+	}
+
+	names := fs.Args()
+	if c.nameFile != "" {
+		more, err := readNames(c.nameFile)
+		if err != nil {
+			fmt.Fprintf(stderr, "gomagic: %s\n", err)
+			return 1
+		}
+		names = append(names, more...)
+	}
+	if len(names) == 0 {
+		fs.Usage()
+		return 2
+	}
+
+	options := []magic.Option{magic.WithFlags(c.flags)}
+	// Errors are reported separately from the results in JSON.
+	if !c.errors && !c.json && !c.ndjson {
+		options = append(options, magic.DoNotStopOnErrors)
+	}
+	if c.flags&magic.DEBUG != 0 {
+		options = append(options, magic.WithDiagnostics(stderr))
+	}
+	for _, p := range c.parameters {
+		options = append(options, magic.WithParameter(p[0], p[1]))
+	}
+	if c.magicFiles != "" {
+		options = append(options, magic.WithFiles(strings.Split(c.magicFiles, ":")...))
+	}
+
+	mgc, err := magic.New(options...)
+	if err != nil {
+		fmt.Fprintf(stderr, "gomagic: %s\n", err)
+		return 1
+	}
+	defer mgc.Close()
+
+	if c.json || c.ndjson {
+		return identifyJSON(mgc, c, names, stdout, stderr)
+	}
+	return identify(mgc, c, names, stdout, stderr)
+}
+
+// identify writes the result for each of the files, and returns the
+// exit code.
+func identify(mgc *magic.Magic, c *config, names []string, stdout, stderr io.Writer) int {
+	width := 0
+	for _, name := range names {
+		if n := len(displayName(name)); n > width {
+			width = n
+		}
+	}
+
+	w := bufio.NewWriter(stdout)
+	defer w.Flush()
+
+	for _, name := range names {
+		var (
+			s   string
+			err error
+		)
+		if name == "-" {
+			s, err = mgc.Descriptor(os.Stdin.Fd())
+		} else {
+			s, err = mgc.File(name)
+		}
+		if err != nil {
+			w.Flush()
+			fmt.Fprintf(stderr, "gomagic: %s\n", err)
+			if c.errors {
+				return 1
+			}
+			continue
+		}
+
+		if !c.brief {
+			name = displayName(name)
+			w.WriteString(name)
+			if c.print0 {
+				w.WriteByte(0)
+			}
+			w.WriteString(c.separator)
+			if !c.noPad {
+				w.WriteString(strings.Repeat(" ", width-len(name)))
+			}
+			w.WriteByte(' ')
+		}
+		w.WriteString(s)
+		w.WriteByte('\n')
+	}
+	return 0
+}
+
+// identifyJSON writes the structured result for each of the files, either
+// as a JSON array, or as one JSON object per line, and returns the exit code.
+func identifyJSON(mgc *magic.Magic, c *config, names []string, stdout, stderr io.Writer) int {
+	var (
+		buffer bytes.Buffer
+		items  []json.RawMessage
+	)
+
+	w := io.Writer(stdout)
+	if c.json {
+		w = &buffer
+	}
+	encoder := magic.NewEncoder(w)
+
+	exitCode := 0
+	for _, name := range names {
+		var (
+			r   magic.Result
+			err error
+		)
+		if name == "-" {
+			r, _, err = magic.Sniff(mgc, os.Stdin)
+		} else {
+			r, err = mgc.FileResult(name)
+		}
+		if err := encoder.Encode(displayName(name), r, err); err != nil {
+			fmt.Fprintf(stderr, "gomagic: %s\n", err)
+			return 1
+		}
+		if c.json {
+			items = append(items, json.RawMessage(append([]byte(nil), buffer.Bytes()...)))
+			buffer.Reset()
+		}
+		if err != nil && c.errors {
+			exitCode = 1
+			break
+		}
+	}
+
+	if c.json {
+		if items == nil {
+			items = []json.RawMessage{}
+		}
+		encoder := json.NewEncoder(stdout)
+		encoder.SetEscapeHTML(false)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(items); err != nil {
+			fmt.Fprintf(stderr, "gomagic: %s\n", err)
+			return 1
+		}
+	}
+	return exitCode
+}
+
+// displayName returns the name of the file as shown alongside the result.
+func displayName(name string) string {
+	if name == "-" {
+		return "/dev/stdin"
+	}
+	return name
+}
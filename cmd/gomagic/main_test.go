@@ -0,0 +1,151 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"path"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/kwilczynski/go-magic"
+)
+
+var fixturesDirectory = path.Join("..", "..", "test", "fixtures")
+
+func TestExpandArgs(t *testing.T) {
+	var expandTests = []struct {
+		given    []string
+		expected []string
+	}{
+		{[]string{"-bi", "file"}, []string{"-b", "-i", "file"}},
+		{[]string{"-bkm", "magic.mgc"}, []string{"-b", "-k", "-m", "magic.mgc"}},
+		{[]string{"-bmmagic.mgc"}, []string{"-b", "-m", "magic.mgc"}},
+		{[]string{"-Pbytes=100"}, []string{"-P", "bytes=100"}},
+		{[]string{"--mime-type", "-mime", "-b"}, []string{"--mime-type", "-mime", "-b"}},
+		{[]string{"--", "-bi"}, []string{"--", "-bi"}},
+	}
+
+	fs := newFlagSet(&config{})
+	for _, tt := range expandTests {
+		if v := expandArgs(fs, tt.given); !reflect.DeepEqual(v, tt.expected) {
+			t.Errorf("value given %q, want %q", v, tt.expected)
+		}
+	}
+}
+
+func TestFlags(t *testing.T) {
+	var flagsTests = []struct {
+		given    []string
+		expected int
+	}{
+		{[]string{"-i"}, magic.MIME},
+		{[]string{"--mime-type", "-k"}, magic.MIME_TYPE | magic.CONTINUE},
+		{[]string{"-Lz"}, magic.SYMLINK | magic.COMPRESS},
+		{[]string{"-L", "-h"}, magic.NONE},
+		{[]string{"-Z", "--extension"}, magic.COMPRESS | magic.COMPRESS_TRANSP | magic.EXTENSION},
+		{[]string{"-e", "soft", "-e", "ascii"}, magic.NO_CHECK_SOFT | magic.NO_CHECK_TEXT},
+	}
+
+	for _, tt := range flagsTests {
+		c := &config{}
+		fs := newFlagSet(c)
+		if err := fs.Parse(expandArgs(fs, tt.given)); err != nil {
+			t.Fatalf("value given {%v}, want {%v}", err, nil)
+		}
+		if c.flags != tt.expected {
+			t.Errorf("value given %s, want %s", magic.Flags(c.flags), magic.Flags(tt.expected))
+		}
+	}
+
+	c := &config{}
+	fs := newFlagSet(c)
+	fs.SetOutput(&bytes.Buffer{})
+	if err := fs.Parse([]string{"-P", "bytes=100", "-P", "indir=10"}); err != nil {
+		t.Fatalf("value given {%v}, want {%v}", err, nil)
+	}
+	expected := [][2]int{{magic.PARAM_BYTES_MAX, 100}, {magic.PARAM_INDIR_MAX, 10}}
+	if !reflect.DeepEqual(c.parameters, expected) {
+		t.Errorf("value given %v, want %v", c.parameters, expected)
+	}
+	for _, args := range [][]string{{"-P", "bytes"}, {"-P", "unknown=1"}, {"-e", "unknown"}} {
+		if err := fs.Parse(args); err == nil {
+			t.Errorf("value given {%v}, want an error for %q", err, args)
+		}
+	}
+}
+
+func TestRun(t *testing.T) {
+	sampleImageFile := path.Join(fixturesDirectory, "gopher.png")
+	fakeMagicFile := path.Join(fixturesDirectory, "png-fake.magic")
+
+	var runTests = []struct {
+		given    []string
+		expected string
+	}{
+		{
+			[]string{"--mime-type", sampleImageFile, fixturesDirectory},
+			sampleImageFile + ": image/png\n" + fixturesDirectory + ":" + strings.Repeat(" ", len("/gopher.png")+1) + "inode/directory\n",
+		},
+		{
+			[]string{"-N", "--mime-type", sampleImageFile, fixturesDirectory},
+			sampleImageFile + ": image/png\n" + fixturesDirectory + ": inode/directory\n",
+		},
+		{
+			[]string{"-bi", sampleImageFile},
+			"image/png; charset=binary\n",
+		},
+		{
+			[]string{"-bm", fakeMagicFile, sampleImageFile},
+			"Go Gopher image, 1634 x 2224, 8-bit/color RGBA, non-interlaced\n",
+		},
+		{
+			[]string{"-0", "-F", "", "--mime-type", sampleImageFile},
+			sampleImageFile + "\x00 image/png\n",
+		},
+	}
+
+	for _, tt := range runTests {
+		var stdout, stderr bytes.Buffer
+		if rv := run(tt.given, &stdout, &stderr); rv != 0 {
+			t.Fatalf("value given %d, want %d: %s", rv, 0, stderr.String())
+		}
+		if v := stdout.String(); v != tt.expected {
+			t.Errorf("value given %q, want %q", v, tt.expected)
+		}
+	}
+
+	var stdout, stderr bytes.Buffer
+	if rv := run([]string{"--ndjson", sampleImageFile, "does-not-exist"}, &stdout, &stderr); rv != 0 {
+		t.Fatalf("value given %d, want %d: %s", rv, 0, stderr.String())
+	}
+	lines := strings.Split(strings.TrimSpace(stdout.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("value given %q, want %d lines", stdout.String(), 2)
+	}
+	var r struct {
+		Path     string `json:"path"`
+		MIMEType string `json:"mime_type"`
+		Error    string `json:"error"`
+	}
+	if err := json.Unmarshal([]byte(lines[0]), &r); err != nil || r.Path != sampleImageFile || r.MIMEType != "image/png" {
+		t.Errorf("value given %+v {%v}, want %q for %q", r, err, "image/png", sampleImageFile)
+	}
+	if err := json.Unmarshal([]byte(lines[1]), &r); err != nil || r.Path != "does-not-exist" || r.Error == "" {
+		t.Errorf("value given %+v {%v}, want an error for %q", r, err, "does-not-exist")
+	}
+
+	stdout.Reset()
+	if rv := run([]string{"--json", sampleImageFile}, &stdout, &stderr); rv != 0 {
+		t.Fatalf("value given %d, want %d: %s", rv, 0, stderr.String())
+	}
+	var results []magic.Result
+	if err := json.Unmarshal(stdout.Bytes(), &results); err != nil || len(results) != 1 || results[0].MIMEType != "image/png" {
+		t.Errorf("value given %+v {%v}, want %q", results, err, "image/png")
+	}
+
+	stdout.Reset()
+	if rv := run([]string{"-E", "does-not-exist"}, &stdout, &stderr); rv != 1 || stderr.Len() == 0 {
+		t.Errorf("value given %d, want %d", rv, 1)
+	}
+}
@@ -0,0 +1,141 @@
+// Command magicfmt formats source Magic files.
+//
+// Usage:
+//
+//	magicfmt [flags] [path ...]
+//
+// Without any paths, it formats the standard input and writes the result
+// to the standard output. Given a directory, it formats every file within
+// the directory. The flags are:
+//
+//	-l
+//		List files whose formatting differs from magicfmt's.
+//	-w
+//		Write the result to the file, rather than the standard output.
+//	-verify
+//		Verify that the result compiles into the same Magic database
+//		as the original file does (default true).
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+
+	"github.com/kwilczynski/go-magic/format"
+	"github.com/kwilczynski/go-magic/internal/paths"
+)
+
+// config represents the options set using flags.
+type config struct {
+	list   bool
+	write  bool
+	verify bool
+}
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdin, os.Stdout, os.Stderr))
+}
+
+func run(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	c := &config{}
+
+	fs := flag.NewFlagSet("magicfmt", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	fs.Usage = func() {
+		fmt.Fprintf(stderr, "usage: magicfmt [flags] [path ...]\n")
+		fs.PrintDefaults()
+	}
+	fs.BoolVar(&c.list, "l", false, "list files whose formatting differs from magicfmt's")
+	fs.BoolVar(&c.write, "w", false, "write result to (source) file instead of stdout")
+	fs.BoolVar(&c.verify, "verify", true, "verify that the result compiles into the same Magic database")
+	if err := fs.Parse(args); err != nil {
+		if err == flag.ErrHelp {
+			return 0
+		}
+		return 2
+	}
+
+	report := func(err error) {
+		fmt.Fprintf(stderr, "magicfmt: %s\n", err)
+	}
+
+	if fs.NArg() == 0 {
+		if c.write {
+			report(fmt.Errorf("cannot use -w with standard input"))
+			return 2
+		}
+		src, err := ioutil.ReadAll(stdin)
+		if err != nil {
+			report(err)
+			return 2
+		}
+		if err := process(c, "<standard input>", src, stdout, nil); err != nil {
+			report(err)
+			return 2
+		}
+		return 0
+	}
+
+	exitCode := 0
+	for _, path := range fs.Args() {
+		files, err := paths.Expand(path)
+		if err != nil {
+			report(err)
+			exitCode = 2
+			continue
+		}
+		for _, file := range files {
+			if err := processFile(c, file, stdout); err != nil {
+				report(err)
+				exitCode = 2
+			}
+		}
+	}
+	return exitCode
+}
+
+func processFile(c *config, file string, stdout io.Writer) error {
+	src, err := ioutil.ReadFile(file)
+	if err != nil {
+		return err
+	}
+	return process(c, file, src, stdout, func(b []byte) error {
+		fi, err := os.Stat(file)
+		if err != nil {
+			return err
+		}
+		return ioutil.WriteFile(file, b, fi.Mode().Perm())
+	})
+}
+
+func process(c *config, name string, src []byte, stdout io.Writer, writeFile func([]byte) error) error {
+	b, err := format.Source(name, src)
+	if err != nil {
+		return err
+	}
+
+	if c.verify {
+		if err := format.Verify(name, src, b); err != nil {
+			return err
+		}
+	}
+
+	changed := !bytes.Equal(src, b)
+	if c.list && changed {
+		fmt.Fprintln(stdout, name)
+	}
+	if c.write {
+		if changed {
+			return writeFile(b)
+		}
+		return nil
+	}
+	if !c.list {
+		_, err = stdout.Write(b)
+	}
+	return err
+}
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const (
+	unformatted = "0    string    GOPHER    gopher data\n>6 byte x \\b, version %d\n"
+	formatted   = "0\tstring\tGOPHER\tgopher data\n>6\tbyte\tx\t\\b, version %d\n"
+)
+
+func TestRun_Stdin(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+
+	if rv := run(nil, strings.NewReader(unformatted), &stdout, &stderr); rv != 0 {
+		t.Fatalf("value given %d, want %d: %s", rv, 0, stderr.String())
+	}
+	if v := stdout.String(); v != formatted {
+		t.Errorf("value given %q, want %q", v, formatted)
+	}
+
+	stdout.Reset()
+	stderr.Reset()
+
+	if rv := run([]string{"-w"}, strings.NewReader(unformatted), &stdout, &stderr); rv != 2 || stderr.Len() == 0 {
+		t.Errorf("value given %d %q, want %d and an error", rv, stderr.String(), 2)
+	}
+
+	stderr.Reset()
+
+	if rv := run(nil, strings.NewReader(">0\tbyte\tx\n"), &stdout, &stderr); rv != 2 || stderr.Len() == 0 {
+		t.Errorf("value given %d %q, want %d and an error", rv, stderr.String(), 2)
+	}
+}
+
+func TestRun_Files(t *testing.T) {
+	dir := t.TempDir()
+
+	changed := filepath.Join(dir, "changed.magic")
+	unchanged := filepath.Join(dir, "unchanged.magic")
+	if err := ioutil.WriteFile(changed, []byte(unformatted), 0644); err != nil {
+		t.Fatalf("unable to write file %q: %s", changed, err.Error())
+	}
+	if err := ioutil.WriteFile(unchanged, []byte(formatted), 0644); err != nil {
+		t.Fatalf("unable to write file %q: %s", unchanged, err.Error())
+	}
+
+	var stdout, stderr bytes.Buffer
+
+	// Only the files whose formatting differs are listed.
+	if rv := run([]string{"-l", dir}, nil, &stdout, &stderr); rv != 0 {
+		t.Fatalf("value given %d, want %d: %s", rv, 0, stderr.String())
+	}
+	if v := changed + "\n"; stdout.String() != v {
+		t.Errorf("value given %q, want %q", stdout.String(), v)
+	}
+
+	stdout.Reset()
+
+	if rv := run([]string{"-w", changed}, nil, &stdout, &stderr); rv != 0 {
+		t.Fatalf("value given %d, want %d: %s", rv, 0, stderr.String())
+	}
+	if stdout.Len() != 0 {
+		t.Errorf("value given %q, want %q", stdout.String(), "")
+	}
+	b, err := ioutil.ReadFile(changed)
+	if err != nil {
+		t.Fatalf("unable to read file %q: %s", changed, err.Error())
+	}
+	if string(b) != formatted {
+		t.Errorf("value given %q, want %q", b, formatted)
+	}
+
+	if rv := run([]string{filepath.Join(dir, "does-not-exist.magic")}, nil, &stdout, &stderr); rv != 2 || stderr.Len() == 0 {
+		t.Errorf("value given %d %q, want %d and an error", rv, stderr.String(), 2)
+	}
+}
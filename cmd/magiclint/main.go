@@ -0,0 +1,92 @@
+// Command magiclint reports likely mistakes in source Magic files.
+//
+// Usage:
+//
+//	magiclint [flags] path ...
+//
+// Given a directory, it lints every file within the directory. All the
+// files are linted together, so that patterns shadowed by patterns from
+// other files are reported. It exits with a status of 1 should any issues
+// be found. The flags are:
+//
+//	-json
+//		Print the issues as a JSON array, for use by other tools.
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/kwilczynski/go-magic/internal/paths"
+	"github.com/kwilczynski/go-magic/lint"
+)
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdout, os.Stderr))
+}
+
+func run(args []string, stdout, stderr io.Writer) int {
+	var jsonOutput bool
+
+	fs := flag.NewFlagSet("magiclint", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	fs.Usage = func() {
+		fmt.Fprintf(stderr, "usage: magiclint [flags] path ...\n")
+		fs.PrintDefaults()
+	}
+	fs.BoolVar(&jsonOutput, "json", false, "print the issues as a JSON array")
+	if err := fs.Parse(args); err != nil {
+		if err == flag.ErrHelp {
+			return 0
+		}
+		return 2
+	}
+
+	if fs.NArg() == 0 {
+		fs.Usage()
+		return 2
+	}
+
+	report := func(err error) int {
+		fmt.Fprintf(stderr, "magiclint: %s\n", err)
+		return 2
+	}
+
+	var files []string
+	for _, path := range fs.Args() {
+		names, err := paths.Expand(path)
+		if err != nil {
+			return report(err)
+		}
+		files = append(files, names...)
+	}
+
+	issues, err := lint.Files(files...)
+	if err != nil {
+		return report(err)
+	}
+
+	if jsonOutput {
+		// Always print an array, even if empty.
+		if issues == nil {
+			issues = []lint.Issue{}
+		}
+		encoder := json.NewEncoder(stdout)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(issues); err != nil {
+			return report(err)
+		}
+	} else {
+		for _, i := range issues {
+			fmt.Fprintln(stdout, i)
+		}
+	}
+
+	if len(issues) > 0 {
+		return 1
+	}
+	return 0
+}
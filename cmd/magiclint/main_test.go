@@ -0,0 +1,60 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"path"
+	"strings"
+	"testing"
+
+	"github.com/kwilczynski/go-magic/lint"
+)
+
+var fixturesDirectory = path.Join("..", "..", "test", "fixtures")
+
+func TestRun(t *testing.T) {
+	shellMagicFile := path.Join(fixturesDirectory, "shell.magic")
+	genuineMagicFile := path.Join(fixturesDirectory, "png.magic")
+
+	var stdout, stderr bytes.Buffer
+
+	if rv := run([]string{shellMagicFile}, &stdout, &stderr); rv != 1 {
+		t.Fatalf("value given %d, want %d: %s", rv, 1, stderr.String())
+	}
+	if lines := strings.Split(strings.TrimSpace(stdout.String()), "\n"); len(lines) != 2 {
+		t.Errorf("value given %q, want %d issues", stdout.String(), 2)
+	}
+
+	stdout.Reset()
+
+	if rv := run([]string{"-json", shellMagicFile}, &stdout, &stderr); rv != 1 {
+		t.Fatalf("value given %d, want %d: %s", rv, 1, stderr.String())
+	}
+	var issues []lint.Issue
+	if err := json.Unmarshal(stdout.Bytes(), &issues); err != nil {
+		t.Fatalf("unable to decode issues: %s", err.Error())
+	}
+	if len(issues) != 2 || issues[0].Check != lint.CheckMissingMIME {
+		t.Errorf("value given %v, want %d issues", issues, 2)
+	}
+
+	stdout.Reset()
+
+	// Always print an array, even if empty.
+	if rv := run([]string{"-json", genuineMagicFile}, &stdout, &stderr); rv != 0 {
+		t.Fatalf("value given %d, want %d: %s", rv, 0, stderr.String())
+	}
+	if v := "[]\n"; stdout.String() != v {
+		t.Errorf("value given %q, want %q", stdout.String(), v)
+	}
+
+	if rv := run(nil, &stdout, &stderr); rv != 2 {
+		t.Errorf("value given %d, want %d", rv, 2)
+	}
+
+	stderr.Reset()
+
+	if rv := run([]string{path.Join(fixturesDirectory, "does-not-exist.magic")}, &stdout, &stderr); rv != 2 || stderr.Len() == 0 {
+		t.Errorf("value given %d %q, want %d and an error", rv, stderr.String(), 2)
+	}
+}
@@ -25,6 +25,7 @@ extern "C" {
 #include <fcntl.h>
 #include <errno.h>
 #include <assert.h>
+#include <pthread.h>
 #include <sys/stat.h>
 #include <magic.h>
 
@@ -0,0 +1,155 @@
+package magic
+
+/*
+#include "functions.h"
+*/
+import "C"
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"unsafe"
+)
+
+// CompileToBytes compiles the source Magic files into a single compiled
+// Magic database, and returns its content, which can then be loaded using
+// LoadBuffers.
+//
+// Unlike Compile, which writes the compiled Magic database file into the
+// current directory, the files are compiled within a private temporary
+// directory, thus this function is safe to use concurrently, and does not
+// require the current directory to be writable.
+//
+// Each of the files can also be a directory, in which case every file
+// within the directory is compiled, in order, as per the Magic library.
+func (mgc *Magic) CompileToBytes(files ...string) ([]byte, error) {
+	buffers, err := readFS(osFS{}, files)
+	if err != nil {
+		return nil, err
+	}
+	return mgc.compile(strings.Join(files, ":"), buffers)
+}
+
+// CompileBuffers compiles the source Magic files from the buffers into
+// a single compiled Magic database, and returns its content, as per
+// the CompileToBytes function.
+func (mgc *Magic) CompileBuffers(buffers ...[]byte) ([]byte, error) {
+	return mgc.compile("", buffers)
+}
+
+// CompileTo compiles the source Magic files into a single compiled
+// Magic database, as per the CompileToBytes function, and writes its
+// content to the writer.
+func (mgc *Magic) CompileTo(w io.Writer, files ...string) error {
+	b, err := mgc.CompileToBytes(files...)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(b)
+	return err
+}
+
+// compile writes the content of each buffer into a file within a
+// temporary directory, and compiles the directory, returning the
+// content of the compiled Magic database file.
+//
+// The Magic library frees the Magic database currently loaded when it
+// compiles, thus the files are compiled using a private Magic library
+// session, leaving the Magic database in use intact.
+func (mgc *Magic) compile(path string, buffers [][]byte) ([]byte, error) {
+	mgc.RLock()
+	defer mgc.RUnlock()
+
+	if err := verifyOpen(mgc); err != nil {
+		return nil, err
+	}
+	if len(buffers) == 0 {
+		err := &Error{int(syscall.ENOENT), "no Magic database files found"}
+		return nil, newOpError(mgc, "compile", path, err)
+	}
+
+	session, err := open()
+	if err != nil {
+		return nil, err
+	}
+	defer session.close()
+
+	dir, err := ioutil.TempDir("", "magic-")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(dir)
+
+	// The Magic library compiles all the files within a directory
+	// into a single compiled Magic database file named after the
+	// directory, and it does so in order of the names of the files.
+	if err := os.Mkdir(filepath.Join(dir, "magic"), 0700); err != nil {
+		return nil, err
+	}
+	for i, b := range buffers {
+		name := filepath.Join(dir, "magic", fmt.Sprintf("%04d", i))
+		if err := ioutil.WriteFile(name, b, 0600); err != nil {
+			return nil, err
+		}
+	}
+
+	err = inDirectory(dir, func() error {
+		cFile := C.CString("magic")
+		defer C.free(unsafe.Pointer(cFile))
+
+		var cRv C.int
+
+		mgc.diagnose(func(output C.int) {
+			cRv = C.magic_compile_wrapper(session.cookie, cFile, C.int(mgc.flags), output)
+		})
+		if cRv < 0 {
+			return newOpError(mgc, "compile", path, session.error())
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return ioutil.ReadFile(filepath.Join(dir, "magic.mgc"))
+}
+
+// CompileToBytes compiles the source Magic files into a single compiled
+// Magic database, and returns its content, as per the CompileToBytes
+// function of the Magic type.
+func CompileToBytes(files ...string) ([]byte, error) {
+	mgc, err := open()
+	if err != nil {
+		return nil, err
+	}
+	defer mgc.close()
+	return mgc.CompileToBytes(files...)
+}
+
+// CompileBuffers compiles the source Magic files from the buffers into
+// a single compiled Magic database, and returns its content, as per the
+// CompileBuffers function of the Magic type.
+func CompileBuffers(buffers ...[]byte) ([]byte, error) {
+	mgc, err := open()
+	if err != nil {
+		return nil, err
+	}
+	defer mgc.close()
+	return mgc.CompileBuffers(buffers...)
+}
+
+// CompileTo compiles the source Magic files into a single compiled
+// Magic database, and writes its content to the writer, as per the
+// CompileTo function of the Magic type.
+func CompileTo(w io.Writer, files ...string) error {
+	mgc, err := open()
+	if err != nil {
+		return err
+	}
+	defer mgc.close()
+	return mgc.CompileTo(w, files...)
+}
@@ -0,0 +1,32 @@
+package magic
+
+import (
+	"runtime"
+	"syscall"
+)
+
+// inDirectory calls the function with the current directory set to the
+// directory, without changing the current directory of the process.
+//
+// The function is called from a separate goroutine locked to its own
+// thread that no longer shares its current directory with the process.
+func inDirectory(dir string, f func() error) error {
+	c := make(chan error, 1)
+
+	go func() {
+		// The thread is never unlocked, so that it is terminated
+		// once the goroutine exits, rather than being reused.
+		runtime.LockOSThread()
+
+		if err := syscall.Unshare(syscall.CLONE_FS); err != nil {
+			c <- err
+			return
+		}
+		if err := syscall.Chdir(dir); err != nil {
+			c <- err
+			return
+		}
+		c <- f()
+	}()
+	return <-c
+}
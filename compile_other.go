@@ -0,0 +1,32 @@
+//go:build !linux
+// +build !linux
+
+package magic
+
+import (
+	"os"
+	"sync"
+)
+
+// Serializes changes to the current directory of the process.
+var directoryMutex sync.Mutex
+
+// inDirectory calls the function with the current directory set to the
+// directory, and restores the current directory of the process afterwards.
+//
+// Any concurrent use of relative paths elsewhere in the process can be
+// affected while the function is being called.
+func inDirectory(dir string, f func() error) error {
+	directoryMutex.Lock()
+	defer directoryMutex.Unlock()
+
+	cwd, err := os.Getwd()
+	if err != nil {
+		return err
+	}
+	if err := os.Chdir(dir); err != nil {
+		return err
+	}
+	defer os.Chdir(cwd)
+	return f()
+}
@@ -0,0 +1,128 @@
+package magic
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path"
+	"testing"
+)
+
+func TestMagic_CompileToBytes(t *testing.T) {
+	mgc, err := New(DisableAutoload, WithFlags(MIME_TYPE))
+	if err != nil {
+		t.Fatalf("unable to create new Magic type: %s", err.Error())
+	}
+	defer mgc.Close()
+
+	fakeMagicFile := path.Join(fixturesDirectory, "png-fake.magic")
+
+	dir := t.TempDir()
+	for _, file := range []string{fakeMagicFile, shellMagicFile} {
+		b, err := ioutil.ReadFile(file)
+		if err != nil {
+			t.Fatalf("unable to read file %q: %s", file, err.Error())
+		}
+		if err := ioutil.WriteFile(path.Join(dir, path.Base(file)), b, 0600); err != nil {
+			t.Fatalf("unable to write file: %s", err.Error())
+		}
+	}
+
+	var compileTests = [][]string{
+		{fakeMagicFile, shellMagicFile},
+		{dir},
+	}
+
+	for _, tt := range compileTests {
+		b, err := mgc.CompileToBytes(tt...)
+		if err != nil {
+			t.Fatalf("value given {%v}, want {%v}", err, nil)
+		}
+		if !isCompiled(b) {
+			t.Fatalf("value given %q..., want a compiled Magic database", b[:4])
+		}
+
+		if err := mgc.LoadBuffers(b); err != nil {
+			t.Fatalf("value given {%v}, want {%v}", err, nil)
+		}
+
+		rv, _ := mgc.File(sampleImageFile)
+		if v := "image/x-go-gopher"; !compareStrings(rv, v) {
+			t.Errorf("value given %q, want %q", rv, v)
+		}
+		rv, _ = mgc.Buffer([]byte("#!/bin/bash\n"))
+		if v := "text/x-shellscript"; !compareStrings(rv, v) {
+			t.Errorf("value given %q, want %q", rv, v)
+		}
+	}
+
+	// Nothing should be written into the current directory.
+	for _, file := range []string{"magic.mgc", "png-fake.magic.mgc"} {
+		if _, err := os.Stat(file); !os.IsNotExist(err) {
+			t.Errorf("value given {%v}, want {%v}", err, os.ErrNotExist)
+		}
+	}
+
+	_, err = mgc.CompileToBytes(brokenMagicFile)
+	if e, ok := err.(*OpError); !ok || e.Op != "compile" || e.Path != brokenMagicFile {
+		t.Errorf("value given {%v}, want an OpError type for %q", err, brokenMagicFile)
+	}
+
+	_, err = mgc.CompileToBytes(path.Join(dir, "does-not-exist"))
+	if !os.IsNotExist(err) {
+		t.Errorf("value given {%v}, want {%v}", err, os.ErrNotExist)
+	}
+}
+
+func TestMagic_CompileToBytes_Loaded(t *testing.T) {
+	mgc, err := New(WithFlags(MIME_TYPE))
+	if err != nil {
+		t.Fatalf("unable to create new Magic type: %s", err.Error())
+	}
+	defer mgc.Close()
+
+	rv, _ := mgc.File(sampleImageFile)
+	if v := "image/png"; !compareStrings(rv, v) {
+		t.Fatalf("value given %q, want %q", rv, v)
+	}
+
+	if _, err := mgc.CompileToBytes(shellMagicFile); err != nil {
+		t.Fatalf("value given {%v}, want {%v}", err, nil)
+	}
+	if _, err := mgc.CompileBuffers([]byte("0 string foo bar\n")); err != nil {
+		t.Fatalf("value given {%v}, want {%v}", err, nil)
+	}
+
+	// The Magic database loaded has to remain in use.
+	rv, _ = mgc.File(sampleImageFile)
+	if v := "image/png"; !compareStrings(rv, v) {
+		t.Errorf("value given %q, want %q", rv, v)
+	}
+	if !mgc.HasLoaded() {
+		t.Errorf("value given %v, want %v", false, true)
+	}
+}
+
+func TestCompileBuffers(t *testing.T) {
+	b, err := ioutil.ReadFile(shellMagicFile)
+	if err != nil {
+		t.Fatalf("unable to read file %q: %s", shellMagicFile, err.Error())
+	}
+
+	compiled, err := CompileBuffers(b)
+	if err != nil {
+		t.Fatalf("value given {%v}, want {%v}", err, nil)
+	}
+
+	var buffer bytes.Buffer
+	if err := CompileTo(&buffer, shellMagicFile); err != nil {
+		t.Fatalf("value given {%v}, want {%v}", err, nil)
+	}
+	if !bytes.Equal(buffer.Bytes(), compiled) {
+		t.Errorf("value given %d bytes, want %d bytes", buffer.Len(), len(compiled))
+	}
+
+	if _, err := CompileBuffers(); err == nil {
+		t.Errorf("value given {%v}, want an error", err)
+	}
+}
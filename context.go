@@ -0,0 +1,172 @@
+package magic
+
+import (
+	"context"
+	"syscall"
+)
+
+// FileContext returns a textual description of the named file, as per
+// File, but returns the error of the context as soon as the context is
+// done, even if the Magic library is still busy.
+//
+// Since the Magic library cannot be interrupted, the Magic object is
+// kept locked until the Magic library returns, thus it will not be
+// used by any other calls, including Close, in the meantime.
+func (mgc *Magic) FileContext(ctx context.Context, file string) (string, error) {
+	return mgc.withContext(ctx, func() (string, error) {
+		return mgc.file(file)
+	})
+}
+
+// BufferContext returns a textual description of the content of the
+// buffer, as per Buffer and FileContext.
+//
+// The content of the buffer is copied, so that the buffer can be
+// safely modified once this function returns.
+func (mgc *Magic) BufferContext(ctx context.Context, buffer []byte) (string, error) {
+	buffer = append([]byte(nil), buffer...)
+	return mgc.withContext(ctx, func() (string, error) {
+		return mgc.buffer(buffer)
+	})
+}
+
+// DescriptorContext returns a textual description of the content of
+// the file descriptor, as per Descriptor and FileContext.
+//
+// The file descriptor is duplicated, so that it can be safely closed
+// once this function returns.
+func (mgc *Magic) DescriptorContext(ctx context.Context, fd uintptr) (string, error) {
+	newFd, err := dup(fd)
+	if err != nil {
+		return "", err
+	}
+	return callContext(ctx, func() (string, error) {
+		defer syscall.Close(int(newFd))
+		return mgc.locked(ctx, func() (string, error) {
+			return mgc.descriptor(newFd)
+		})
+	})
+}
+
+// withContext calls the function with the lock held, returning
+// the error of the context as soon as the context is done.
+func (mgc *Magic) withContext(ctx context.Context, f func() (string, error)) (string, error) {
+	return callContext(ctx, func() (string, error) {
+		return mgc.locked(ctx, f)
+	})
+}
+
+// locked calls the function with the lock held, unless the context
+// has been done while waiting for the lock.
+func (mgc *Magic) locked(ctx context.Context, f func() (string, error)) (string, error) {
+	mgc.Lock()
+	defer mgc.Unlock()
+
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+	return f()
+}
+
+// FileContext returns a textual description of the named file, as
+// per the FileContext function of the Magic type.
+//
+// The Magic object used is not returned to the pool until the Magic
+// library returns, even if the context is done earlier.
+func (p *Pool) FileContext(ctx context.Context, file string) (string, error) {
+	return p.withContext(ctx, func(mgc *Magic) (string, error) {
+		return mgc.File(file)
+	})
+}
+
+// BufferContext returns a textual description of the content of the
+// buffer, as per the BufferContext function of the Magic type and
+// the FileContext function of the Pool type.
+func (p *Pool) BufferContext(ctx context.Context, buffer []byte) (string, error) {
+	buffer = append([]byte(nil), buffer...)
+	return p.withContext(ctx, func(mgc *Magic) (string, error) {
+		return mgc.Buffer(buffer)
+	})
+}
+
+// DescriptorContext returns a textual description of the content of
+// the file descriptor, as per the DescriptorContext function of the
+// Magic type and the FileContext function of the Pool type.
+func (p *Pool) DescriptorContext(ctx context.Context, fd uintptr) (string, error) {
+	mgc, err := p.get(ctx)
+	if err != nil {
+		return "", err
+	}
+	// The file descriptor is duplicated only once the Magic object
+	// has been acquired, as otherwise it would never be closed.
+	newFd, err := dup(fd)
+	if err != nil {
+		p.put(mgc)
+		return "", err
+	}
+	return p.callContext(ctx, mgc, func(mgc *Magic) (string, error) {
+		defer syscall.Close(int(newFd))
+		return mgc.Descriptor(newFd)
+	})
+}
+
+// withContext calls the function with the Magic object from the pool,
+// returning the error of the context as soon as the context is done.
+func (p *Pool) withContext(ctx context.Context, f func(*Magic) (string, error)) (string, error) {
+	mgc, err := p.get(ctx)
+	if err != nil {
+		return "", err
+	}
+	return p.callContext(ctx, mgc, f)
+}
+
+// callContext calls the function with the Magic object acquired from
+// the pool, as per withContext, and then returns it to the pool.
+func (p *Pool) callContext(ctx context.Context, mgc *Magic, f func(*Magic) (string, error)) (string, error) {
+	return callContext(ctx, func() (string, error) {
+		// The Magic object is returned to the pool only once the
+		// call completes, regardless of whether the context is done.
+		defer p.put(mgc)
+		return f(mgc)
+	})
+}
+
+// callContext calls the function in a separate goroutine, returning
+// either its result or the error of the context, whichever is first.
+//
+// The function is always called, even if the context is already done,
+// thus it is up to the function to release any resources it holds.
+func callContext(ctx context.Context, f func() (string, error)) (string, error) {
+	type result struct {
+		s   string
+		err error
+	}
+	c := make(chan result, 1)
+
+	go func() {
+		var r result
+		r.s, r.err = f()
+		c <- r
+	}()
+
+	select {
+	case r := <-c:
+		return r.s, r.err
+	case <-ctx.Done():
+		return "", ctx.Err()
+	}
+}
+
+// dup duplicates the file descriptor.
+func dup(fd uintptr) (uintptr, error) {
+	newFd, err := syscall.Dup(int(fd))
+	if err != nil {
+		errno := err.(syscall.Errno)
+		if errno == syscall.EBADF {
+			return 0, ErrBadDescriptor
+		}
+		return 0, &Error{int(errno), errno.Error()}
+	}
+	syscall.CloseOnExec(newFd)
+	return uintptr(newFd), nil
+}
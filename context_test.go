@@ -0,0 +1,196 @@
+package magic
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestMagic_FileContext(t *testing.T) {
+	mgc, err := New(WithFlags(MIME_TYPE))
+	if err != nil {
+		t.Fatalf("unable to create new Magic type: %s", err.Error())
+	}
+	defer mgc.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	rv, err := mgc.FileContext(ctx, sampleImageFile)
+	if err != nil {
+		t.Fatalf("value given {%v}, want {%v}", err, nil)
+	}
+	if v := "image/png"; !compareStrings(rv, v) {
+		t.Errorf("value given %q, want %q", rv, v)
+	}
+
+	cancel()
+
+	_, err = mgc.FileContext(ctx, sampleImageFile)
+	if err != context.Canceled {
+		t.Errorf("value given {%v}, want {%v}", err, context.Canceled)
+	}
+}
+
+func TestMagic_BufferContext_Locked(t *testing.T) {
+	mgc, err := New()
+	if err != nil {
+		t.Fatalf("unable to create new Magic type: %s", err.Error())
+	}
+	defer mgc.Close()
+
+	// Simulate a call that is still in progress.
+	mgc.Lock()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	_, err = mgc.BufferContext(ctx, []byte("Hello, World!"))
+	if err != context.DeadlineExceeded {
+		t.Errorf("value given {%v}, want {%v}", err, context.DeadlineExceeded)
+	}
+
+	mgc.Unlock()
+}
+
+func TestMagic_DescriptorContext(t *testing.T) {
+	mgc, err := New(WithFlags(MIME_TYPE))
+	if err != nil {
+		t.Fatalf("unable to create new Magic type: %s", err.Error())
+	}
+	defer mgc.Close()
+
+	f, err := os.Open(sampleImageFile)
+	if err != nil {
+		t.Fatalf("unable to open file %q: %s", sampleImageFile, err.Error())
+	}
+	defer f.Close()
+
+	rv, err := mgc.DescriptorContext(context.Background(), f.Fd())
+	if err != nil {
+		t.Fatalf("value given {%v}, want {%v}", err, nil)
+	}
+	if v := "image/png"; !compareStrings(rv, v) {
+		t.Errorf("value given %q, want %q", rv, v)
+	}
+
+	_, err = mgc.DescriptorContext(context.Background(), uintptr(1<<16))
+
+	v := "magic: bad file descriptor"
+	if err == nil || !compareStrings(err.Error(), v) {
+		t.Errorf("value given {%v}, want {%q}", err, v)
+	}
+}
+
+func TestPool_FileContext(t *testing.T) {
+	p, err := NewPool(1, WithFlags(MIME_TYPE))
+	if err != nil {
+		t.Fatalf("unable to create new Pool type: %s", err.Error())
+	}
+	defer p.Close()
+
+	rv, err := p.FileContext(context.Background(), sampleImageFile)
+	if err != nil {
+		t.Fatalf("value given {%v}, want {%v}", err, nil)
+	}
+	if v := "image/png"; !compareStrings(rv, v) {
+		t.Errorf("value given %q, want %q", rv, v)
+	}
+
+	// Hold the only Magic object, so that the next call has to wait.
+	mgc, err := p.get(context.Background())
+	if err != nil {
+		t.Fatalf("value given {%v}, want {%v}", err, nil)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	_, err = p.FileContext(ctx, sampleImageFile)
+	if err != context.DeadlineExceeded {
+		t.Errorf("value given {%v}, want {%v}", err, context.DeadlineExceeded)
+	}
+
+	p.put(mgc)
+
+	if stats := p.Stats(); stats.WaitCount != 1 || stats.InUse != 0 {
+		t.Errorf("value given %+v, want a single wait and none in use", stats)
+	}
+}
+
+func TestPool_DescriptorContext(t *testing.T) {
+	p, err := NewPool(1, WithFlags(MIME_TYPE))
+	if err != nil {
+		t.Fatalf("unable to create new Pool type: %s", err.Error())
+	}
+	defer p.Close()
+
+	f, err := os.Open(sampleImageFile)
+	if err != nil {
+		t.Fatalf("unable to open file %q: %s", sampleImageFile, err.Error())
+	}
+	defer f.Close()
+
+	rv, err := p.DescriptorContext(context.Background(), f.Fd())
+	if err != nil {
+		t.Fatalf("value given {%v}, want {%v}", err, nil)
+	}
+	if v := "image/png"; !compareStrings(rv, v) {
+		t.Errorf("value given %q, want %q", rv, v)
+	}
+
+	_, err = p.DescriptorContext(context.Background(), uintptr(1<<16))
+	if err != ErrBadDescriptor {
+		t.Errorf("value given {%v}, want {%v}", err, ErrBadDescriptor)
+	}
+	if stats := p.Stats(); stats.InUse != 0 {
+		t.Errorf("value given %+v, want none in use", stats)
+	}
+}
+
+func TestPool_DescriptorContext_Leak(t *testing.T) {
+	// Count the open file descriptors of the process, which is only
+	// possible where the proc file system is available.
+	openDescriptors := func() int {
+		entries, err := ioutil.ReadDir("/proc/self/fd")
+		if err != nil {
+			t.Skipf("unable to list open file descriptors: %s", err.Error())
+		}
+		return len(entries)
+	}
+
+	p, err := NewPool(1, WithFlags(MIME_TYPE))
+	if err != nil {
+		t.Fatalf("unable to create new Pool type: %s", err.Error())
+	}
+	defer p.Close()
+
+	f, err := os.Open(sampleImageFile)
+	if err != nil {
+		t.Fatalf("unable to open file %q: %s", sampleImageFile, err.Error())
+	}
+	defer f.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	before := openDescriptors()
+	for i := 0; i < 100; i++ {
+		if _, err := p.DescriptorContext(ctx, f.Fd()); err != context.Canceled {
+			t.Fatalf("value given {%v}, want {%v}", err, context.Canceled)
+		}
+	}
+
+	p.Close()
+
+	for i := 0; i < 100; i++ {
+		if _, err := p.DescriptorContext(context.Background(), f.Fd()); err != ErrNotOpen {
+			t.Fatalf("value given {%v}, want {%v}", err, ErrNotOpen)
+		}
+	}
+	if after := openDescriptors(); after != before {
+		t.Errorf("value given %d, want %d", after, before)
+	}
+}
@@ -0,0 +1,81 @@
+package magic
+
+import (
+	"mime"
+	"runtime"
+	"sync"
+)
+
+const (
+	// The MIME type returned when the content cannot be identified.
+	defaultContentType = "application/octet-stream"
+
+	// The MIME type returned for empty content, as per the net/http package.
+	emptyContentType = "text/plain; charset=utf-8"
+)
+
+var (
+	detectOnce sync.Once
+	detectPool *Pool
+)
+
+// DetectContentType implements the same contract as the DetectContentType
+// function of the net/http package, and can be used as a replacement, but
+// identifies the content using the Magic library, which knows considerably
+// more types of content, and considers more than the first 512 bytes.
+//
+// It always returns a valid MIME type, with the charset parameter for
+// content that is text, e.g., "text/plain; charset=utf-8", and falls
+// back to "application/octet-stream" if the content cannot be identified.
+//
+// As per the net/http package, empty content is "text/plain; charset=utf-8",
+// and text that is ASCII is reported as UTF-8, of which ASCII is a subset.
+// The results differ otherwise where the Magic library is more specific,
+// for example, a shell script is "text/x-shellscript; charset=utf-8",
+// rather than "text/plain; charset=utf-8", and text in other encodings
+// retains the charset detected, e.g., "iso-8859-1".
+//
+// The Magic library is shared by all the calls, and it is opened and the
+// default Magic database is loaded when the function is first called.
+// Should either fail, then the fallback MIME type is always returned.
+func DetectContentType(data []byte) string {
+	if len(data) == 0 {
+		return emptyContentType
+	}
+
+	detectOnce.Do(func() {
+		// The error is not kept, as the contract offers no way
+		// to report it, thus the fallback is returned instead.
+		detectPool, _ = NewPool(runtime.NumCPU(), WithFlags(MIME))
+	})
+	if detectPool == nil {
+		return defaultContentType
+	}
+
+	s, err := detectPool.Buffer(data)
+	if err != nil {
+		return defaultContentType
+	}
+	return contentType(s)
+}
+
+// contentType returns the MIME identification as a valid MIME type,
+// omitting the "binary" charset and reporting the "us-ascii" charset as
+// "utf-8", as per the net/http package, or the fallback MIME type should
+// the MIME identification not be valid.
+func contentType(s string) string {
+	mediaType, parameters, err := mime.ParseMediaType(s)
+	if err != nil {
+		return defaultContentType
+	}
+	switch parameters["charset"] {
+	case "binary":
+		delete(parameters, "charset")
+	case "us-ascii":
+		parameters["charset"] = "utf-8"
+	}
+	if s = mime.FormatMediaType(mediaType, parameters); s == "" {
+		return defaultContentType
+	}
+	return s
+}
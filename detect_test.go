@@ -0,0 +1,70 @@
+package magic
+
+import (
+	"io/ioutil"
+	"net/http"
+	"testing"
+)
+
+func TestDetectContentType(t *testing.T) {
+	image, err := ioutil.ReadFile(sampleImageFile)
+	if err != nil {
+		t.Fatalf("unable to read file %q: %s", sampleImageFile, err.Error())
+	}
+
+	var detectTests = []struct {
+		given    []byte
+		expected string
+	}{
+		{image, "image/png"},
+		{image[:512], "image/png"},
+		{[]byte("#!/bin/bash\n\n"), "text/x-shellscript; charset=utf-8"},
+		{[]byte("Gr\xfc\xdfe aus K\xf6ln, liebe Gr\xfc\xdfe\n"), "text/plain; charset=iso-8859-1"},
+		{nil, "text/plain; charset=utf-8"},
+		{[]byte("Hello, 世界"), "text/plain; charset=utf-8"},
+		{[]byte{0x00, 0x01, 0x02, 0x03}, "application/octet-stream"},
+	}
+
+	for _, tt := range detectTests {
+		if v := DetectContentType(tt.given); !compareStrings(v, tt.expected) {
+			t.Errorf("value given %q, want %q", v, tt.expected)
+		}
+	}
+}
+
+func TestDetectContentType_HTTP(t *testing.T) {
+	var httpTests = [][]byte{
+		nil,
+		{},
+		[]byte("<!DOCTYPE html>\n<html><head><title>Gopher</title></head><body><p>Hello, World!</p></body></html>\n"),
+		[]byte("Hello, World!\n"),
+		[]byte("Hello, 世界\n"),
+	}
+
+	for _, given := range httpTests {
+		v := http.DetectContentType(given)
+		if rv := DetectContentType(given); rv != v {
+			t.Errorf("value given %q, want %q for %q", rv, v, given)
+		}
+	}
+}
+
+func TestContentType(t *testing.T) {
+	var contentTypeTests = []struct {
+		given    string
+		expected string
+	}{
+		{"image/png; charset=binary", "image/png"},
+		{"text/plain; charset=us-ascii", "text/plain; charset=utf-8"},
+		{"text/plain; charset=utf-16le", "text/plain; charset=utf-16le"},
+		{"application/x-empty; charset=binary", "application/x-empty"},
+		{"", "application/octet-stream"},
+		{"not a MIME type", "application/octet-stream"},
+	}
+
+	for _, tt := range contentTypeTests {
+		if v := contentType(tt.given); !compareStrings(v, tt.expected) {
+			t.Errorf("value given %q, want %q", v, tt.expected)
+		}
+	}
+}
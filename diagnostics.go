@@ -0,0 +1,102 @@
+package magic
+
+/*
+#include "functions.h"
+*/
+import "C"
+
+import (
+	"io"
+	"io/ioutil"
+	"os"
+)
+
+// WithDiagnostics sets the writer to which the diagnostic output of
+// the Magic library is delivered, as per the SetDiagnostics function.
+func WithDiagnostics(w io.Writer) Option {
+	return func(mgc *Magic) error {
+		return mgc.SetDiagnostics(w)
+	}
+}
+
+// SetDiagnostics sets the writer to which the diagnostic output of the
+// Magic library, such as debugging messages when the DEBUG flag is set,
+// or warnings about the Magic database files when the CHECK flag is set,
+// is delivered, rather than being either discarded or written to the
+// standard error output of the process.
+//
+// The output is captured separately for each call to the Magic library,
+// and then written to the writer at once after the call returns. Setting
+// the writer to nil restores the default behavior.
+func (mgc *Magic) SetDiagnostics(w io.Writer) error {
+	mgc.Lock()
+	defer mgc.Unlock()
+
+	if err := verifyOpen(mgc); err != nil {
+		return err
+	}
+
+	mgc.outputMutex.Lock()
+	defer mgc.outputMutex.Unlock()
+
+	if w == nil {
+		if mgc.output != nil {
+			mgc.output.Close()
+		}
+		mgc.diagnostics, mgc.output = nil, nil
+		return nil
+	}
+
+	if mgc.output == nil {
+		f, err := newOutput()
+		if err != nil {
+			return err
+		}
+		mgc.output = f
+	}
+	mgc.diagnostics = w
+	return nil
+}
+
+// diagnose calls the function with a file descriptor to which the
+// Magic library should write its diagnostic output, or -1 if the
+// output is to be discarded, and then delivers the output captured,
+// if any, to the writer set.
+func (mgc *Magic) diagnose(f func(C.int)) {
+	if mgc.output == nil {
+		f(-1)
+		return
+	}
+
+	mgc.outputMutex.Lock()
+	defer mgc.outputMutex.Unlock()
+	capture(mgc.output, mgc.diagnostics, f)
+}
+
+// capture calls the function with the file descriptor of the file
+// capturing the diagnostic output, and then writes the output that
+// was captured, if any, to the writer.
+func capture(output *os.File, w io.Writer, f func(C.int)) {
+	f(C.int(output.Fd()))
+
+	if _, err := output.Seek(0, io.SeekStart); err != nil {
+		return
+	}
+	if b, err := ioutil.ReadAll(output); err == nil && len(b) > 0 {
+		w.Write(b)
+	}
+	output.Truncate(0)
+	output.Seek(0, io.SeekStart)
+}
+
+// newOutput creates a new file to capture the diagnostic output.
+func newOutput() (*os.File, error) {
+	f, err := ioutil.TempFile("", "magic-diagnostics-")
+	if err != nil {
+		return nil, err
+	}
+	// The file is no longer needed by its name, and will
+	// be removed once closed.
+	os.Remove(f.Name())
+	return f, nil
+}
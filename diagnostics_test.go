@@ -0,0 +1,60 @@
+package magic
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestMagic_SetDiagnostics(t *testing.T) {
+	var buffer bytes.Buffer
+
+	mgc, err := New(DisableAutoload, WithFlags(CHECK), WithDiagnostics(&buffer))
+	if err != nil {
+		t.Fatalf("unable to create new Magic type: %s", err.Error())
+	}
+	defer mgc.Close()
+
+	if _, err := mgc.Check(shellMagicFile); err != nil {
+		t.Fatalf("value given {%v}, want {%v}", err, nil)
+	}
+
+	v := "Warning: using regular magic file `" + shellMagicFile + "'"
+	if s := buffer.String(); !strings.Contains(s, v) {
+		t.Errorf("value given %q, want %q", s, v)
+	}
+
+	buffer.Reset()
+
+	if err := mgc.SetDiagnostics(nil); err != nil {
+		t.Fatalf("value given {%v}, want {%v}", err, nil)
+	}
+	if _, err := mgc.Check(shellMagicFile); err != nil {
+		t.Fatalf("value given {%v}, want {%v}", err, nil)
+	}
+	if buffer.Len() > 0 {
+		t.Errorf("value given %q, should be empty", buffer.String())
+	}
+}
+
+func TestMagic_SetDiagnostics_Debug(t *testing.T) {
+	var buffer bytes.Buffer
+
+	mgc, err := New(WithDiagnostics(&buffer))
+	if err != nil {
+		t.Fatalf("unable to create new Magic type: %s", err.Error())
+	}
+	defer mgc.Close()
+
+	if err := mgc.SetFlags(DEBUG); err != nil {
+		t.Fatalf("unable to set flags: %s", err.Error())
+	}
+	if _, err := mgc.Buffer([]byte("Hello, World!\n")); err != nil {
+		t.Fatalf("value given {%v}, want {%v}", err, nil)
+	}
+
+	v := "[try zmagic 0]"
+	if s := buffer.String(); !strings.Contains(s, v) {
+		t.Errorf("value given %q, want %q", s, v)
+	}
+}
@@ -2,6 +2,29 @@ package magic
 
 import (
 	"fmt"
+	"syscall"
+)
+
+var (
+	// ErrNotOpen is returned when the Magic library is not open.
+	ErrNotOpen = &Error{int(syscall.EFAULT), "Magic library is not open"}
+
+	// ErrNotLoaded is returned when the Magic database is not loaded.
+	ErrNotLoaded = &Error{-1, "Magic database not loaded"}
+
+	// ErrInvalidFlag is returned when an unknown or invalid flag is specified.
+	ErrInvalidFlag = &Error{int(syscall.EINVAL), "unknown or invalid flag specified"}
+
+	// ErrInvalidParameter is returned when an unknown or invalid parameter
+	// is specified.
+	ErrInvalidParameter = &Error{int(syscall.EINVAL), "unknown or invalid parameter specified"}
+
+	// ErrBadDescriptor is returned when a file descriptor is not valid.
+	ErrBadDescriptor = &Error{int(syscall.EBADF), "bad file descriptor"}
+
+	// ErrEmptyResult is returned when the Magic library yields no results
+	// or the result is not valid.
+	ErrEmptyResult = &Error{-1, "empty or invalid result"}
 )
 
 // Error represents an error originating from the underlying Magic library.
@@ -14,3 +37,46 @@ type Error struct {
 func (e *Error) Error() string {
 	return fmt.Sprintf("magic: %s", e.Message)
 }
+
+// Is returns true if the target is an Error with both the same errno
+// and message, so that errors.Is can be used to test for any of the
+// sentinel errors, such as ErrNotLoaded, or false otherwise.
+func (e *Error) Is(target error) bool {
+	t, ok := target.(*Error)
+	return ok && e.Errno == t.Errno && e.Message == t.Message
+}
+
+// Unwrap returns the value of errno as the syscall.Errno type, if any,
+// so that errors.Is can be used to test for errors such as, for example,
+// os.ErrNotExist, or nil otherwise.
+func (e *Error) Unwrap() error {
+	if e.Errno > 0 {
+		return syscall.Errno(e.Errno)
+	}
+	return nil
+}
+
+// OpError represents an error that occurred during an operation on a
+// file or a file descriptor, such as File or Load, and records which
+// file it was, and the flags in effect at the time.
+type OpError struct {
+	Op    string // The operation e.g., "file", "load", etc.
+	Path  string // The file, list of files, or file descriptor.
+	Flags Flags  // The flags in effect.
+	Err   error  // The underlying error, usually of the Error type.
+}
+
+// Error returns a descriptive error message.
+func (e *OpError) Error() string {
+	message := e.Err.Error()
+	if err, ok := e.Err.(*Error); ok {
+		message = err.Message
+	}
+	return fmt.Sprintf("magic: %s %s: %s", e.Op, e.Path, message)
+}
+
+// Unwrap returns the underlying error, so that errors.Is can be used
+// to test for any of the sentinel errors, such as ErrBadDescriptor.
+func (e *OpError) Unwrap() error {
+	return e.Err
+}
@@ -1,7 +1,10 @@
 package magic
 
 import (
+	"errors"
+	"os"
 	"reflect"
+	"strings"
 	"testing"
 )
 
@@ -20,6 +23,128 @@ func TestError(t *testing.T) {
 	}(err)
 }
 
+func TestError_Is(t *testing.T) {
+	mgc, err := New(DisableAutoload)
+	if err != nil {
+		t.Fatalf("unable to create new Magic type: %s", err.Error())
+	}
+	defer mgc.Close()
+
+	var errorTests = []struct {
+		given    error
+		expected error
+	}{
+		{
+			func() error { _, err := mgc.File(sampleImageFile); return err }(),
+			ErrNotLoaded,
+		},
+		{
+			mgc.SetFlags(-1),
+			ErrInvalidFlag,
+		},
+		{
+			mgc.SetParameter(-1, 0),
+			ErrInvalidParameter,
+		},
+		{
+			func() error { _, err := mgc.Parameter(-1); return err }(),
+			ErrInvalidParameter,
+		},
+		{
+			func() error { _, err := ParseFlags("does-not-exist"); return err }(),
+			ErrInvalidFlag,
+		},
+		{
+			func() error { mgc.Load(); _, err := mgc.Descriptor(uintptr(1 << 16)); return err }(),
+			ErrBadDescriptor,
+		},
+		{
+			func() error { _, err := mgc.File("does/not/exist"); return err }(),
+			os.ErrNotExist,
+		},
+		{
+			&Error{-1, "empty or invalid result"},
+			ErrEmptyResult,
+		},
+		{
+			func() error { mgc.Close(); _, err := mgc.File(sampleImageFile); return err }(),
+			ErrNotOpen,
+		},
+	}
+
+	for _, tt := range errorTests {
+		if !errors.Is(tt.given, tt.expected) {
+			t.Errorf("value given {%v}, want {%v}", tt.given, tt.expected)
+		}
+	}
+
+	if err := (&Error{-1, "an unknown error has occurred"}); errors.Is(err, ErrNotLoaded) {
+		t.Errorf("value given {%v}, should not be {%v}", err, ErrNotLoaded)
+	}
+}
+
+func TestOpError(t *testing.T) {
+	mgc, err := New(WithFlags(MIME_TYPE))
+	if err != nil {
+		t.Fatalf("unable to create new Magic type: %s", err.Error())
+	}
+	defer mgc.Close()
+
+	var opErrorTests = []struct {
+		given    error
+		op, path string
+		message  string
+		expected error
+	}{
+		{
+			func() error { _, err := mgc.File("does/not/exist"); return err }(),
+			"file", "does/not/exist",
+			"magic: file does/not/exist: ",
+			os.ErrNotExist,
+		},
+		{
+			func() error { _, err := mgc.Descriptor(uintptr(1 << 16)); return err }(),
+			"descriptor", "fd 65536",
+			"magic: descriptor fd 65536: ",
+			ErrBadDescriptor,
+		},
+		{
+			mgc.Load("does/not/exist"),
+			"load", "does/not/exist",
+			"magic: load does/not/exist: ",
+			nil,
+		},
+		{
+			func() error { _, err := mgc.Check(brokenMagicFile); return err }(),
+			"check", brokenMagicFile,
+			"magic: check " + brokenMagicFile + ": ",
+			nil,
+		},
+	}
+
+	for _, tt := range opErrorTests {
+		e, ok := tt.given.(*OpError)
+		if !ok {
+			t.Errorf("value given {%v}, want an OpError type", tt.given)
+			continue
+		}
+		if e.Op != tt.op || e.Path != tt.path {
+			t.Errorf("value given %q %q, want %q %q", e.Op, e.Path, tt.op, tt.path)
+		}
+		if v := Flags(MIME_TYPE); e.Flags != v {
+			t.Errorf("value given %s, want %s", e.Flags, v)
+		}
+		// The message of the underlying error varies between
+		// different versions of the Magic library.
+		if !strings.HasPrefix(e.Error(), tt.message) {
+			t.Errorf("value given %q, want prefix %q", e.Error(), tt.message)
+		}
+		if tt.expected != nil && !errors.Is(e, tt.expected) {
+			t.Errorf("value given {%v}, want {%v}", e, tt.expected)
+		}
+	}
+}
+
 // func TestError_Error(t *testing.T) {
 // 	var v string
 
@@ -58,3 +58,14 @@ func ExampleBufferEncoding() {
 	// Output:
 	// Data in the buffer is encoded as: utf-8
 }
+
+// This example shows how to identify the content type of a buffer,
+// as a replacement for the DetectContentType function of the net/http
+// package.
+func ExampleDetectContentType() {
+	buffer := []byte("#!/bin/bash\n\n")
+
+	fmt.Printf("Content type is: %s\n", magic.DetectContentType(buffer))
+	// Output:
+	// Content type is: text/x-shellscript; charset=utf-8
+}
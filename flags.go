@@ -0,0 +1,179 @@
+package magic
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Flags represents a set of flags (bitmask) that can be set to
+// change the results and/or behavior of the Magic library.
+type Flags int
+
+// flagNames maps each distinct flag (bit) to its name.
+var flagNames = []struct {
+	flag Flags
+	name string
+}{
+	{DEBUG, "DEBUG"},
+	{SYMLINK, "SYMLINK"},
+	{COMPRESS, "COMPRESS"},
+	{DEVICES, "DEVICES"},
+	{MIME_TYPE, "MIME_TYPE"},
+	{CONTINUE, "CONTINUE"},
+	{CHECK, "CHECK"},
+	{PRESERVE_ATIME, "PRESERVE_ATIME"},
+	{RAW, "RAW"},
+	{ERROR, "ERROR"},
+	{MIME_ENCODING, "MIME_ENCODING"},
+	{APPLE, "APPLE"},
+	{EXTENSION, "EXTENSION"},
+	{COMPRESS_TRANSP, "COMPRESS_TRANSP"},
+	{NO_CHECK_COMPRESS, "NO_CHECK_COMPRESS"},
+	{NO_CHECK_TAR, "NO_CHECK_TAR"},
+	{NO_CHECK_SOFT, "NO_CHECK_SOFT"},
+	{NO_CHECK_APPTYPE, "NO_CHECK_APPTYPE"},
+	{NO_CHECK_ELF, "NO_CHECK_ELF"},
+	{NO_CHECK_TEXT, "NO_CHECK_TEXT"},
+	{NO_CHECK_CDF, "NO_CHECK_CDF"},
+	{NO_CHECK_CSV, "NO_CHECK_CSV"},
+	{NO_CHECK_TOKENS, "NO_CHECK_TOKENS"},
+	{NO_CHECK_ENCODING, "NO_CHECK_ENCODING"},
+	{NO_CHECK_JSON, "NO_CHECK_JSON"},
+}
+
+// flagAliases maps names of flags that are a combination of other
+// flags, or are otherwise not distinct, to their value.
+var flagAliases = map[string]Flags{
+	"NONE":             NONE,
+	"MIME":             MIME,
+	"NO_CHECK_ASCII":   NO_CHECK_ASCII,
+	"NO_CHECK_BUILTIN": NO_CHECK_BUILTIN,
+	"NO_CHECK_FORTRAN": NO_CHECK_FORTRAN,
+	"NO_CHECK_TROFF":   NO_CHECK_TROFF,
+}
+
+// knownFlags is a bitmask of all the flags known.
+var knownFlags Flags
+
+func init() {
+	// Depending on the version of the Magic library, some of
+	// the flags might not be supported, and are then set to 0.
+	names := flagNames[:0]
+	for _, f := range flagNames {
+		if f.flag != 0 {
+			names = append(names, f)
+			knownFlags |= f.flag
+		}
+	}
+	sort.Slice(names, func(i, j int) bool {
+		return names[i].flag < names[j].flag
+	})
+	flagNames = names
+}
+
+// ParseFlags parses a list of flag names separated by a comma or the
+// "|" character, e.g., "mime-type,continue" or "MIME_TYPE|CONTINUE",
+// and returns the flags set accordingly.
+//
+// Names are case-insensitive, and both "-" and "_" can be used within
+// a name. The numeric value of the flags can also be given instead of
+// a name. An empty string yields the NONE flag.
+func ParseFlags(s string) (Flags, error) {
+	var flags Flags
+
+	fields := strings.FieldsFunc(s, func(r rune) bool {
+		return r == ',' || r == '|'
+	})
+	for _, field := range fields {
+		name := strings.TrimSpace(field)
+		if name == "" {
+			continue
+		}
+		if n, err := strconv.ParseInt(name, 0, 0); err == nil {
+			flags |= Flags(n)
+			continue
+		}
+		if f, ok := lookupFlag(name); ok {
+			flags |= f
+			continue
+		}
+		return NONE, ErrInvalidFlag
+	}
+	if err := flags.Validate(); err != nil {
+		return NONE, err
+	}
+	return flags, nil
+}
+
+// lookupFlag returns the flag of the given name.
+func lookupFlag(name string) (Flags, bool) {
+	name = strings.ToUpper(strings.Replace(name, "-", "_", -1))
+	if f, ok := flagAliases[name]; ok {
+		return f, true
+	}
+	for _, f := range flagNames {
+		if f.name == name {
+			return f.flag, true
+		}
+	}
+	return NONE, false
+}
+
+// String returns a string representation of the flags, with the name
+// of each distinct flag set separated by the "|" character, e.g.,
+// "MIME_TYPE|CONTINUE", or "NONE" if no flags are set.
+func (f Flags) String() string {
+	if f == NONE {
+		return "NONE"
+	}
+
+	var names []string
+	for _, flag := range flagNames {
+		if f&flag.flag != 0 {
+			names = append(names, flag.name)
+		}
+	}
+	if unknown := f &^ knownFlags; unknown != 0 {
+		names = append(names, fmt.Sprintf("0x%x", int(unknown)))
+	}
+	return strings.Join(names, "|")
+}
+
+// Has returns true if all of the given flags are set, or false otherwise.
+func (f Flags) Has(flags Flags) bool {
+	return f&flags == flags
+}
+
+// With returns the flags with the given flags set.
+func (f Flags) With(flags Flags) Flags {
+	return f | flags
+}
+
+// Without returns the flags with the given flags cleared.
+func (f Flags) Without(flags Flags) Flags {
+	return f &^ flags
+}
+
+// Slice returns a slice containing each distinct flag that is set.
+//
+// Results are sorted in an ascending order.
+func (f Flags) Slice() []Flags {
+	var flags []Flags
+	for i := Flags(1); i > 0 && i <= f; i <<= 1 {
+		if f&i != 0 {
+			flags = append(flags, i)
+		}
+	}
+	return flags
+}
+
+// Validate returns an error if any of the flags set is not known,
+// or if the value of the flags is not valid, or nil otherwise.
+func (f Flags) Validate() error {
+	if f < 0 || f&^knownFlags != 0 {
+		return ErrInvalidFlag
+	}
+	return nil
+}
@@ -0,0 +1,133 @@
+package magic
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestFlags_String(t *testing.T) {
+	var stringTests = []struct {
+		given    Flags
+		expected string
+	}{
+		{NONE, "NONE"},
+		{MIME_TYPE, "MIME_TYPE"},
+		{MIME_TYPE | CONTINUE, "MIME_TYPE|CONTINUE"},
+		{MIME, "MIME_TYPE|MIME_ENCODING"},
+		{SYMLINK | 1<<30, "SYMLINK|0x40000000"},
+	}
+
+	for _, tt := range stringTests {
+		if s := tt.given.String(); s != tt.expected {
+			t.Errorf("value given %q, want %q", s, tt.expected)
+		}
+	}
+}
+
+func TestParseFlags(t *testing.T) {
+	var parseTests = []struct {
+		given    string
+		expected Flags
+		err      bool
+	}{
+		{"", NONE, false},
+		{"none", NONE, false},
+		{"mime-type,continue", MIME_TYPE | CONTINUE, false},
+		{"MIME_TYPE|CONTINUE", MIME_TYPE | CONTINUE, false},
+		{" mime , symlink ", MIME | SYMLINK, false},
+		{"0x10", MIME_TYPE, false},
+		{"no-check-ascii", NO_CHECK_TEXT, false},
+		{"mime-type,does-not-exist", NONE, true},
+		{"0x40000000", NONE, true},
+	}
+
+	for _, tt := range parseTests {
+		flags, err := ParseFlags(tt.given)
+		if (err != nil) != tt.err {
+			t.Errorf("value given {%v} for %q, want error %t", err, tt.given, tt.err)
+		}
+		if flags != tt.expected {
+			t.Errorf("value given %s for %q, want %s", flags, tt.given, tt.expected)
+		}
+	}
+}
+
+func TestFlags_Helpers(t *testing.T) {
+	flags := Flags(NONE).With(MIME).With(CONTINUE)
+
+	if ok := flags.Has(MIME_TYPE | CONTINUE); !ok {
+		t.Errorf("value given %v, want %v", ok, true)
+	}
+
+	flags = flags.Without(MIME_ENCODING)
+	if ok := flags.Has(MIME); ok {
+		t.Errorf("value given %v, want %v", ok, false)
+	}
+
+	v := []Flags{MIME_TYPE, CONTINUE}
+	if !reflect.DeepEqual(flags.Slice(), v) {
+		t.Errorf("value given %v, want %v", flags.Slice(), v)
+	}
+}
+
+func TestMagic_FlagsSlice(t *testing.T) {
+	mgc, err := New()
+	if err != nil {
+		t.Fatalf("unable to create new Magic type: %s", err.Error())
+	}
+	defer mgc.Close()
+
+	flags, _ := ParseFlags("mime,continue,extension")
+	if err := mgc.SetFlags(int(flags)); err != nil {
+		t.Fatalf("unable to set flags: %s", err.Error())
+	}
+
+	rv, _ := mgc.FlagsSlice()
+
+	v := []int{MIME_TYPE, CONTINUE, MIME_ENCODING, EXTENSION}
+	if !reflect.DeepEqual(rv, v) {
+		t.Errorf("value given %v, want %v", rv, v)
+	}
+}
+
+func TestMagic_Flags(t *testing.T) {
+	flags, err := ParseFlags("mime-type|raw")
+	if err != nil {
+		t.Fatalf("value given {%v}, want {%v}", err, nil)
+	}
+
+	mgc, err := New(WithFlags(int(flags)))
+	if err != nil {
+		t.Fatalf("unable to create new Magic type: %s", err.Error())
+	}
+	defer mgc.Close()
+
+	rv, _ := mgc.FlagsSlice()
+	if v := []int{MIME_TYPE, RAW}; !reflect.DeepEqual(rv, v) {
+		t.Errorf("value given %v, want %v", rv, v)
+	}
+
+	if err := mgc.SetFlags(int(flags.Without(RAW).With(EXTENSION))); err != nil {
+		t.Fatalf("value given {%v}, want {%v}", err, nil)
+	}
+	actual, err := mgc.Flags()
+	if err != nil {
+		t.Fatalf("value given {%v}, want {%v}", err, nil)
+	}
+	if v := "MIME_TYPE|EXTENSION"; !compareStrings(Flags(actual).String(), v) {
+		t.Errorf("value given %q, want %q", Flags(actual).String(), v)
+	}
+
+	// A plain int value has to be accepted as well.
+	var value int = MIME_ENCODING
+	if err := mgc.SetFlags(value); err != nil {
+		t.Fatalf("value given {%v}, want {%v}", err, nil)
+	}
+	if actual, _ := mgc.Flags(); actual != value {
+		t.Errorf("value given %d, want %d", actual, value)
+	}
+
+	if err := mgc.SetFlags(-1); err != ErrInvalidFlag {
+		t.Errorf("value given {%v}, want {%v}", err, ErrInvalidFlag)
+	}
+}
@@ -0,0 +1,37 @@
+package format_test
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+
+	"github.com/kwilczynski/go-magic"
+	"github.com/kwilczynski/go-magic/format"
+)
+
+// This example shows how to format a source Magic file, and to verify
+// that the result is the same once compiled.
+func Example() {
+	src, err := ioutil.ReadFile("../test/fixtures/shell.magic")
+	if err != nil {
+		panic(fmt.Sprintf("Unable to read Magic file: %s\n", err))
+	}
+
+	formatted, err := format.Source("shell.magic", src)
+	if err != nil {
+		panic(fmt.Sprintf("Unable to format Magic file: %s\n", err))
+	}
+
+	before, err := magic.CompileBuffers(src)
+	if err != nil {
+		panic(fmt.Sprintf("Unable to compile Magic file: %s\n", err))
+	}
+	after, err := magic.CompileBuffers(formatted)
+	if err != nil {
+		panic(fmt.Sprintf("Unable to compile Magic file: %s\n", err))
+	}
+
+	fmt.Printf("Equivalent: %v\n", bytes.Equal(before, after))
+	// Output:
+	// Equivalent: true
+}
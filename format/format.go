@@ -0,0 +1,182 @@
+/*
+Package format implements formatting of the source Magic files, as described
+in the magic(5) manual page.
+
+The formatting aligns the offset, type, test and message of the entries into
+columns using tabs, removes any whitespace following the continuation levels,
+and normalizes the escapes, while preserving comments, and the line on which
+each entry, directive and comment appears, so that the compiled Magic database
+remains the same, which the Verify function checks using the Magic library.
+*/
+package format
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/kwilczynski/go-magic"
+	"github.com/kwilczynski/go-magic/parse"
+)
+
+// The width of a tab, as assumed when aligning the columns.
+const tabWidth = 8
+
+// Source formats the source Magic file, and returns the result. The name
+// is used in the error messages only.
+//
+// The source Magic file has to be valid, as per the Parse function of the
+// parse package, otherwise the errors are returned.
+func Source(name string, src []byte) ([]byte, error) {
+	f, err := parse.Parse(name, bytes.NewReader(src))
+	if err != nil {
+		return nil, err
+	}
+	return Node(f), nil
+}
+
+// ErrChanged is returned by Verify should the formatting change the
+// compiled Magic database.
+var ErrChanged = errors.New("formatting changes the compiled Magic database")
+
+// Verify compiles both the source Magic file and the result of formatting
+// it, and returns an error wrapping ErrChanged should these not compile into
+// the same Magic database, or the error should either fail to compile. The
+// name is used in the error messages only.
+func Verify(name string, src, formatted []byte) error {
+	before, err := magic.CompileBuffers(src)
+	if err != nil {
+		return fmt.Errorf("%s: %w", name, err)
+	}
+	after, err := magic.CompileBuffers(formatted)
+	if err != nil {
+		return fmt.Errorf("%s: %w", name, err)
+	}
+	if !bytes.Equal(before, after) {
+		return fmt.Errorf("%s: %w", name, ErrChanged)
+	}
+	return nil
+}
+
+// Node formats the syntax tree of the source Magic file, and returns
+// the result.
+func Node(f *parse.File) []byte {
+	var rows []*row
+
+	add := func(r *row) {
+		for len(rows) < r.line {
+			rows = append(rows, &row{line: len(rows) + 1})
+		}
+		rows[r.line-1] = r
+	}
+
+	for _, c := range f.Comments {
+		add(&row{line: c.Line, text: c.Text})
+	}
+	f.Walk(func(e *parse.Entry) {
+		add(&row{
+			line: e.Line,
+			cells: []string{
+				strings.Repeat(">", e.Level) + e.Offset,
+				e.Type,
+				normalize(e.Test),
+			},
+			text: e.Message,
+		})
+		for _, d := range e.Directives {
+			// Directives are part of the block, but are not aligned.
+			add(&row{line: d.Line, cells: []string{}, text: "!:" + d.Name + "\t" + d.Value})
+		}
+	})
+
+	var buffer bytes.Buffer
+
+	// Entries and directives on consecutive lines form a block, within
+	// which the columns are aligned, whereas comments and empty lines
+	// separate the blocks.
+	for i := 0; i < len(rows); {
+		j := i
+		for j < len(rows) && rows[j].cells != nil {
+			j++
+		}
+		if i == j {
+			buffer.WriteString(rows[i].text)
+			buffer.WriteByte('\n')
+			i++
+			continue
+		}
+		writeBlock(&buffer, rows[i:j])
+		i = j
+	}
+	return buffer.Bytes()
+}
+
+// row represents a single line of the source Magic file, with the cells
+// that are to be aligned, if any, followed by the text, if any. The cells
+// are nil for the lines that are not part of any block.
+type row struct {
+	line  int
+	cells []string
+	text  string
+}
+
+// writeBlock writes the rows with the cells aligned into columns.
+func writeBlock(buffer *bytes.Buffer, rows []*row) {
+	var widths []int
+	for _, r := range rows {
+		for i, cell := range r.cells {
+			if i == len(widths) {
+				widths = append(widths, 0)
+			}
+			if len(cell) > widths[i] {
+				widths[i] = len(cell)
+			}
+		}
+	}
+
+	for _, r := range rows {
+		position := 0
+		for i, cell := range r.cells {
+			buffer.WriteString(cell)
+			// Nothing follows the last cell without any text.
+			if i == len(r.cells)-1 && r.text == "" {
+				break
+			}
+			position += len(cell)
+			next := (position-len(cell)+widths[i])/tabWidth*tabWidth + tabWidth
+			for position < next {
+				buffer.WriteByte('\t')
+				position = position/tabWidth*tabWidth + tabWidth
+			}
+		}
+		buffer.WriteString(r.text)
+		buffer.WriteByte('\n')
+	}
+}
+
+// normalize normalizes the escapes within the test, such that the hex
+// digits of any hex escapes are in lower case, e.g., "\x0d" for "\x0D".
+func normalize(s string) string {
+	b := []byte(s)
+	for i := 0; i < len(b); i++ {
+		if b[i] != '\\' || i+1 == len(b) {
+			continue
+		}
+		i++
+		if b[i] != 'x' {
+			continue
+		}
+		for j := i + 1; j < len(b) && j <= i+2 && isHex(b[j]); j++ {
+			if b[j] >= 'A' && b[j] <= 'F' {
+				b[j] += 'a' - 'A'
+			}
+		}
+	}
+	return string(b)
+}
+
+// isHex returns true if the character is a hex digit, or false otherwise.
+func isHex(c byte) bool {
+	return c >= '0' && c <= '9' || c >= 'a' && c <= 'f' || c >= 'A' && c <= 'F'
+}
@@ -0,0 +1,144 @@
+package format
+
+import (
+	"errors"
+	"io/ioutil"
+	"path"
+	"strings"
+	"testing"
+)
+
+var fixturesDirectory = path.Join("..", "test", "fixtures")
+
+func TestSource(t *testing.T) {
+	src := strings.Join([]string{
+		`# Shell scripts`,
+		`0       string/wt       #!\ /bin/sh             POSIX shell script`,
+		`!:mime  text/x-shellscript`,
+		``,
+		`0	string/t	#!/	a`,
+		`> 2	string	>\0	%s	script`,
+		`>>&0 byte	x`,
+		`!:strength / 2`,
+		`0	string	\x0D\x0A\x0g`,
+	}, "\n")
+
+	b, err := Source("test.magic", []byte(src))
+	if err != nil {
+		t.Fatalf("value given {%v}, want {%v}", err, nil)
+	}
+
+	v := strings.Join([]string{
+		`# Shell scripts`,
+		"0\tstring/wt\t#!\\ /bin/sh\tPOSIX shell script",
+		"!:mime\ttext/x-shellscript",
+		``,
+		"0\tstring/t\t#!/\t\ta",
+		">2\tstring\t\t>\\0\t\t%s\tscript",
+		">>&0\tbyte\t\tx",
+		"!:strength\t/ 2",
+		"0\tstring\t\t\\x0d\\x0a\\x0g",
+		``,
+	}, "\n")
+	if string(b) != v {
+		t.Errorf("value given %q, want %q", b, v)
+	}
+
+	_, err = Source("test.magic", []byte(">0\tbyte\tx\n"))
+	if err == nil {
+		t.Errorf("value given {%v}, want an error", err)
+	}
+}
+
+func TestSource_Columns(t *testing.T) {
+	src := "0\tstring\tlong\\ test\\ value\tone\n>16\tbelong\tx\ttwo\n>>100000000\tbyte\tx\tthree\n"
+
+	b, err := Source("test.magic", []byte(src))
+	if err != nil {
+		t.Fatalf("value given {%v}, want {%v}", err, nil)
+	}
+
+	v := "" +
+		"0\t\tstring\tlong\\ test\\ value\tone\n" +
+		">16\t\tbelong\tx\t\t\ttwo\n" +
+		">>100000000\tbyte\tx\t\t\tthree\n"
+	if string(b) != v {
+		t.Errorf("value given %q, want %q", b, v)
+	}
+}
+
+func TestSource_Idempotent(t *testing.T) {
+	for _, name := range []string{"png.magic", "png-fake.magic", "png-warning.magic", "shell.magic"} {
+		src, err := ioutil.ReadFile(path.Join(fixturesDirectory, name))
+		if err != nil {
+			t.Fatalf("unable to read file %q: %s", name, err.Error())
+		}
+
+		b, err := Source(name, src)
+		if err != nil {
+			t.Fatalf("value given {%v}, want {%v}", err, nil)
+		}
+		if n, m := strings.Count(string(src), "\n"), strings.Count(string(b), "\n"); n != m {
+			t.Errorf("value given %d lines, want %d lines", m, n)
+		}
+
+		again, err := Source(name, b)
+		if err != nil {
+			t.Fatalf("value given {%v}, want {%v}", err, nil)
+		}
+		if string(again) != string(b) {
+			t.Errorf("value given %q, want %q", again, b)
+		}
+	}
+}
+
+func TestSource_Equivalent(t *testing.T) {
+	for _, name := range []string{"png.magic", "png-fake.magic", "png-warning.magic", "shell.magic"} {
+		src, err := ioutil.ReadFile(path.Join(fixturesDirectory, name))
+		if err != nil {
+			t.Fatalf("unable to read file %q: %s", name, err.Error())
+		}
+
+		b, err := Source(name, src)
+		if err != nil {
+			t.Fatalf("value given {%v}, want {%v}", err, nil)
+		}
+
+		if err := Verify(name, src, b); err != nil {
+			t.Errorf("value given {%v}, want {%v}", err, nil)
+		}
+	}
+}
+
+func TestVerify(t *testing.T) {
+	src := []byte("0\tstring\tGOPHER\tgopher data\n")
+
+	var verifyTests = []struct {
+		formatted []byte
+		changed   bool
+		failed    bool
+	}{
+		{[]byte("0\tstring\tGOPHER\tgopher data\n"), false, false},
+		{[]byte("0 string GOPHER gopher data\n"), false, false},
+		{[]byte("0\tstring\tGOPHER\tgopher file\n"), true, true},
+		{[]byte("0\tstring\tGOPHER\n>4\tbyte\tx\tmore\n"), true, true},
+		{[]byte("0\tnotatype\tGOPHER\tgopher data\n"), false, true},
+	}
+
+	for _, tt := range verifyTests {
+		err := Verify("test.magic", src, tt.formatted)
+		if (err != nil) != tt.failed {
+			t.Errorf("value given {%v}, want an error %v for %q", err, tt.failed, tt.formatted)
+		}
+		if errors.Is(err, ErrChanged) != tt.changed {
+			t.Errorf("value given {%v}, want {%v} for %q", err, ErrChanged, tt.formatted)
+		}
+		if err != nil && !strings.HasPrefix(err.Error(), "test.magic: ") {
+			t.Errorf("value given %q, want the name as a prefix", err.Error())
+		}
+	}
+
+	if err := Verify("broken.magic", []byte("0\tnotatype\tx\n"), src); err == nil {
+		t.Errorf("value given {%v}, want an error", err)
+	}
+}
@@ -0,0 +1,258 @@
+package magic
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io/fs"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"syscall"
+)
+
+// The magic number that compiled Magic database files begin with,
+// stored in the byte order of the machine it was compiled on.
+const compiledMagic = 0xF11E041C
+
+// WithFS loads the Magic database files from the file system,
+// as per the LoadFS function.
+func WithFS(fsys fs.FS, names ...string) Option {
+	return func(mgc *Magic) error {
+		return mgc.LoadFS(fsys, names...)
+	}
+}
+
+// FS returns a textual description of the named file from the file
+// system (or MIME identification, etc., depending on the flags set),
+// such as one provided by the embed.FS type, the os.DirFS function,
+// or an adapter for an archive, as per File.
+//
+// Should the file opened expose its file descriptor, as the os.File
+// type does, then the file descriptor is used, as per Descriptor,
+// otherwise at most as many bytes as the current value of the
+// PARAM_BYTES_MAX parameter permits are read, as per Reader.
+//
+// Directories are described as the Magic library would describe them
+// when using File, without ever reading their content. Should either the
+// EXTENSION or APPLE flag be set, then an error wrapping syscall.EISDIR
+// is returned instead, even when I/O-related errors are not reported.
+func (mgc *Magic) FS(fsys fs.FS, name string) (string, error) {
+	f, err := fsys.Open(name)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	fi, err := f.Stat()
+	if err != nil {
+		return "", err
+	}
+	if fi.IsDir() {
+		return mgc.directory(name)
+	}
+
+	if d, ok := f.(interface{ Fd() uintptr }); ok && fi.Mode().IsRegular() {
+		return mgc.Descriptor(d.Fd())
+	}
+
+	n, err := mgc.Parameter(PARAM_BYTES_MAX)
+	if err != nil {
+		return "", err
+	}
+
+	buffer, err := readPrefix(f, n)
+	if err != nil {
+		return "", &fs.PathError{Op: "read", Path: name, Err: err}
+	}
+	return mgc.Buffer(buffer)
+}
+
+// LoadFS loads the Magic database files from the file system, such as
+// one provided by the embed.FS type, so that the Magic database can be
+// bundled with the program, rather than loaded from the default location
+// on the filesystem.
+//
+// Both compiled Magic database files and source Magic files are supported.
+// Should all the files be compiled, then these are loaded from memory, as
+// per LoadBuffers, otherwise the files are copied into a temporary directory
+// and loaded from there, as per Load, which compiles the source Magic files
+// on the fly.
+//
+// Each of the names can also refer to a directory, in which case every file
+// within the directory is loaded, in order, just like the Magic library does.
+// Should no names be given, then the root directory of the file system is
+// loaded.
+func (mgc *Magic) LoadFS(fsys fs.FS, names ...string) error {
+	mgc.Lock()
+	defer mgc.Unlock()
+
+	if err := verifyOpen(mgc); err != nil {
+		return err
+	}
+	if len(names) == 0 {
+		names = []string{"."}
+	}
+
+	buffers, err := readFS(fsys, names)
+	if err != nil {
+		return err
+	}
+	if len(buffers) == 0 {
+		err := &Error{int(syscall.ENOENT), "no Magic database files found"}
+		return newOpError(mgc, "load", strings.Join(names, ":"), err)
+	}
+
+	compiled := true
+	for _, b := range buffers {
+		if !isCompiled(b) {
+			compiled = false
+			break
+		}
+	}
+
+	if compiled {
+		err = mgc.loadBuffers(buffers...)
+	} else {
+		err = mgc.loadTemporary(buffers)
+	}
+	if err != nil {
+		// Report the names from the file system rather than
+		// the names of any of the temporary files.
+		if e, ok := err.(*OpError); ok {
+			err = e.Err
+		}
+		return newOpError(mgc, "load", strings.Join(names, ":"), err)
+	}
+	// There are no paths on the filesystem to report
+	// for the Magic database files that are in use.
+	mgc.paths = []string{}
+	return nil
+}
+
+// loadTemporary writes the content of each buffer into a file within a
+// temporary directory, and loads the files from there, the lock has to
+// be held by the caller.
+func (mgc *Magic) loadTemporary(buffers [][]byte) error {
+	dir, err := ioutil.TempDir("", "magic-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(dir)
+
+	files := make([]string, len(buffers))
+	for i, b := range buffers {
+		// The Magic library recognizes compiled Magic database
+		// files by their extension, and would otherwise attempt
+		// to parse these as source Magic files.
+		name := fmt.Sprintf("%04d", i)
+		if isCompiled(b) {
+			name += ".mgc"
+		}
+		files[i] = filepath.Join(dir, name)
+		if err := ioutil.WriteFile(files[i], b, 0600); err != nil {
+			return err
+		}
+	}
+	return mgc.load(files...)
+}
+
+// readFS reads the content of each of the named files from the
+// file system, including the files within any of the directories.
+func readFS(fsys fs.FS, names []string) ([][]byte, error) {
+	var buffers [][]byte
+
+	for _, name := range names {
+		fi, err := fs.Stat(fsys, name)
+		if err != nil {
+			return nil, err
+		}
+		if !fi.IsDir() {
+			b, err := fs.ReadFile(fsys, name)
+			if err != nil {
+				return nil, err
+			}
+			buffers = append(buffers, b)
+			continue
+		}
+
+		// Entries are sorted by name, and any directories
+		// within are skipped, as per the Magic library.
+		entries, err := fs.ReadDir(fsys, name)
+		if err != nil {
+			return nil, err
+		}
+		for _, entry := range entries {
+			if !entry.Type().IsRegular() {
+				continue
+			}
+			b, err := fs.ReadFile(fsys, path.Join(name, entry.Name()))
+			if err != nil {
+				return nil, err
+			}
+			buffers = append(buffers, b)
+		}
+	}
+	return buffers, nil
+}
+
+// osFS implements the fs.FS interface for the file system of the
+// operating system, and, unlike os.DirFS, it accepts any path that
+// the os package does, such as absolute paths.
+type osFS struct{}
+
+// Open opens the named file, as per the Open function of the os package.
+func (osFS) Open(name string) (fs.File, error) {
+	return os.Open(name)
+}
+
+// isCompiled returns true if the content is that of a compiled
+// Magic database file, in either byte order, or false otherwise.
+func isCompiled(b []byte) bool {
+	if len(b) < 4 {
+		return false
+	}
+	return binary.LittleEndian.Uint32(b) == compiledMagic ||
+		binary.BigEndian.Uint32(b) == compiledMagic
+}
+
+// directory returns a textual description of a directory depending
+// on the flags set, matching what the Magic library would return.
+func (mgc *Magic) directory(name string) (string, error) {
+	mgc.RLock()
+	defer mgc.RUnlock()
+
+	if err := verifyOpen(mgc); err != nil {
+		return "", err
+	}
+	if err := verifyLoaded(mgc); err != nil {
+		return "", err
+	}
+
+	// Neither the file extensions nor the Apple creator and
+	// type are known for directories, thus the Magic library
+	// attempts to read the directory, which fails, as per File.
+	flags := mgc.flags
+	switch {
+	case flags&(EXTENSION|APPLE) != 0:
+		return "", &fs.PathError{Op: "read", Path: name, Err: syscall.EISDIR}
+	case flags.Has(MIME):
+		return "inode/directory; charset=binary", nil
+	case flags.Has(MIME_TYPE):
+		return "inode/directory", nil
+	case flags.Has(MIME_ENCODING):
+		return "binary", nil
+	}
+	return "directory", nil
+}
+
+// FS returns a textual description of the named file from the
+// file system, as per the FS function of the Magic type.
+func (p *Pool) FS(fsys fs.FS, name string) (s string, err error) {
+	err = p.do(func(mgc *Magic) (err error) {
+		s, err = mgc.FS(fsys, name)
+		return
+	})
+	return
+}
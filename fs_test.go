@@ -0,0 +1,206 @@
+package magic
+
+import (
+	"errors"
+	"io/fs"
+	"io/ioutil"
+	"os"
+	"path"
+	"runtime"
+	"syscall"
+	"testing"
+	"testing/fstest"
+)
+
+func TestMagic_FS(t *testing.T) {
+	mgc, err := New(WithFlags(MIME_TYPE))
+	if err != nil {
+		t.Fatalf("unable to create new Magic type: %s", err.Error())
+	}
+	defer mgc.Close()
+
+	b, err := ioutil.ReadFile(sampleImageFile)
+	if err != nil {
+		t.Fatalf("unable to read file %q: %s", sampleImageFile, err.Error())
+	}
+
+	var fsTests = []struct {
+		fsys fs.FS
+		name string
+	}{
+		// Uses the file descriptor of the os.File type.
+		{os.DirFS(fixturesDirectory), "gopher.png"},
+		// Reads the content of the file into a buffer.
+		{fstest.MapFS{"images/gopher.png": {Data: b}}, "images/gopher.png"},
+	}
+
+	for _, tt := range fsTests {
+		rv, err := mgc.FS(tt.fsys, tt.name)
+		if err != nil {
+			t.Fatalf("value given {%v}, want {%v}", err, nil)
+		}
+		if v := "image/png"; !compareStrings(rv, v) {
+			t.Errorf("value given %q, want %q", rv, v)
+		}
+	}
+
+	_, err = mgc.FS(os.DirFS(fixturesDirectory), "does-not-exist.png")
+	if !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("value given {%v}, want {%v}", err, fs.ErrNotExist)
+	}
+}
+
+func TestMagic_FS_Directory(t *testing.T) {
+	var directoryTests = []struct {
+		options []Option
+		flags   []int
+	}{
+		{nil, []int{NONE, MIME, MIME_TYPE, MIME_ENCODING}},
+		{[]Option{DoNotStopOnErrors}, []int{NONE, MIME_TYPE}},
+	}
+
+	fsys := fstest.MapFS{path.Join(testDirectory, "gopher.png"): {Data: []byte{}}}
+
+	for _, tt := range directoryTests {
+		mgc, err := New(tt.options...)
+		if err != nil {
+			t.Fatalf("unable to create new Magic type: %s", err.Error())
+		}
+
+		for _, flags := range tt.flags {
+			if err := mgc.SetFlags(flags); err != nil {
+				t.Fatalf("value given {%v}, want {%v}", err, nil)
+			}
+
+			// Both should agree on how a directory is described.
+			v, _ := mgc.File(testDirectory)
+			rv, err := mgc.FS(fsys, testDirectory)
+			if err != nil {
+				t.Errorf("value given {%v}, want {%v}", err, nil)
+			}
+			if rv != v {
+				t.Errorf("value given %q, want %q for %s", rv, v, Flags(flags))
+			}
+		}
+
+		for _, flags := range []int{EXTENSION, APPLE, MIME_TYPE | EXTENSION} {
+			if err := mgc.SetFlags(flags); err != nil {
+				t.Fatalf("value given {%v}, want {%v}", err, nil)
+			}
+
+			rv, err := mgc.FS(fsys, testDirectory)
+			if rv != "" {
+				t.Errorf("value given %q, want %q for %s", rv, "", Flags(flags))
+			}
+			if e, ok := err.(*fs.PathError); !ok || e.Path != testDirectory || !errors.Is(err, syscall.EISDIR) {
+				t.Errorf("value given {%v}, want a PathError type for %q", err, testDirectory)
+			}
+		}
+		mgc.Close()
+	}
+}
+
+func TestMagic_LoadFS(t *testing.T) {
+	mgc, err := New(DisableAutoload, WithFlags(MIME_TYPE))
+	if err != nil {
+		t.Fatalf("unable to create new Magic type: %s", err.Error())
+	}
+	defer mgc.Close()
+
+	fakeMagic, err := ioutil.ReadFile(path.Join(fixturesDirectory, "png-fake.magic"))
+	if err != nil {
+		t.Fatalf("unable to read file: %s", err.Error())
+	}
+	shellMagic, err := ioutil.ReadFile(shellMagicFile)
+	if err != nil {
+		t.Fatalf("unable to read file: %s", err.Error())
+	}
+	compiledMagic, err := CompileBuffers(fakeMagic)
+	if err != nil {
+		t.Fatalf("unable to compile Magic file: %s", err.Error())
+	}
+
+	var loadFSTests = []struct {
+		fsys  fs.FS
+		names []string
+	}{
+		{os.DirFS(fixturesDirectory), []string{"png-fake.magic"}},
+		{fstest.MapFS{"magic.mgc": {Data: compiledMagic}}, []string{"magic.mgc"}},
+		{fstest.MapFS{"magic": {Data: compiledMagic}}, nil},
+		{fstest.MapFS{"magic/png": {Data: fakeMagic}, "magic/shell/sh": {Data: shellMagic}}, []string{"magic"}},
+		{fstest.MapFS{"png": {Data: compiledMagic}, "sh": {Data: shellMagic}}, []string{"sh", "png"}},
+	}
+
+	for _, tt := range loadFSTests {
+		if err := mgc.LoadFS(tt.fsys, tt.names...); err != nil {
+			t.Fatalf("value given {%v}, want {%v}", err, nil)
+		}
+
+		rv, err := mgc.File(sampleImageFile)
+		if err != nil {
+			t.Fatalf("value given {%v}, want {%v}", err, nil)
+		}
+		if v := "image/x-go-gopher"; !compareStrings(rv, v) {
+			t.Errorf("value given %q, want %q", rv, v)
+		}
+	}
+
+	rv, _ := mgc.Buffer([]byte("#!/bin/bash\n"))
+	if v := "text/x-shellscript"; !compareStrings(rv, v) {
+		t.Errorf("value given %q, want %q", rv, v)
+	}
+
+	err = mgc.LoadFS(os.DirFS(fixturesDirectory), "does-not-exist.magic")
+	if !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("value given {%v}, want {%v}", err, fs.ErrNotExist)
+	}
+
+	err = mgc.LoadFS(os.DirFS(fixturesDirectory), "png-broken.magic")
+	if e, ok := err.(*OpError); !ok || e.Path != "png-broken.magic" {
+		t.Errorf("value given {%v}, want an OpError type for %q", err, "png-broken.magic")
+	}
+	if mgc.HasLoaded() {
+		t.Errorf("value given %v, want %v", true, false)
+	}
+}
+
+func TestMagic_LoadFS_GC(t *testing.T) {
+	mgc, err := New(DisableAutoload, WithFlags(MIME_TYPE))
+	if err != nil {
+		t.Fatalf("unable to create new Magic type: %s", err.Error())
+	}
+	defer mgc.Close()
+
+	fakeMagic, err := ioutil.ReadFile(path.Join(fixturesDirectory, "png-fake.magic"))
+	if err != nil {
+		t.Fatalf("unable to read file: %s", err.Error())
+	}
+	compiledMagic, err := CompileBuffers(fakeMagic)
+	if err != nil {
+		t.Fatalf("unable to compile Magic file: %s", err.Error())
+	}
+
+	// The content read from the file system is not referenced
+	// by the caller, and has to remain valid after it is loaded.
+	if err := mgc.LoadFS(fstest.MapFS{"magic.mgc": {Data: compiledMagic}}); err != nil {
+		t.Fatalf("value given {%v}, want {%v}", err, nil)
+	}
+	compiledMagic = nil
+
+	var garbage [][]byte
+	for i := 0; i < 3; i++ {
+		runtime.GC()
+		for j := 0; j < 1024; j++ {
+			garbage = append(garbage, make([]byte, 1024))
+		}
+	}
+	runtime.GC()
+
+	rv, err := mgc.File(sampleImageFile)
+	if err != nil {
+		t.Fatalf("value given {%v}, want {%v}", err, nil)
+	}
+	if v := "image/x-go-gopher"; !compareStrings(rv, v) {
+		t.Errorf("value given %q, want %q", rv, v)
+	}
+}
@@ -8,8 +8,13 @@ int check_fd(int fd);
 static int safe_dup(int fd);
 static int safe_close(int fd);
 static int safe_cloexec(int fd);
-int override_error_output(void *data);
-int restore_error_output(void *data);
+static void batch_result(magic_t magic, batch_t *batch, const char *cstring);
+static int file_batch(magic_t magic, const char **filenames, size_t count,
+		      batch_t *batch);
+static int buffer_batch(magic_t magic, void **buffers, size_t *sizes,
+			size_t count, batch_t *batch);
+
+static pthread_mutex_t error_output_mutex = PTHREAD_MUTEX_INITIALIZER;
 
 inline int
 check_fd(int fd)
@@ -89,8 +94,20 @@ error:
 	return -1;
 }
 
+inline void
+lock_error_output(void)
+{
+	pthread_mutex_lock(&error_output_mutex);
+}
+
+inline void
+unlock_error_output(void)
+{
+	pthread_mutex_unlock(&error_output_mutex);
+}
+
 int
-override_error_output(void *data)
+override_error_output(void *data, int fd)
 {
 	int local_errno;
 	mode_t mode = S_IRWXU | S_IRWXG | S_IRWXO;
@@ -116,7 +133,15 @@ override_error_output(void *data)
 		goto error;
 	}
 
-	s->file.new_fd = open("/dev/null", O_WRONLY | O_APPEND, mode);
+	/*
+	 * Send the output to the file descriptor given, if any,
+	 * otherwise discard it.
+	 */
+	if (fd < 0)
+		s->file.new_fd = open("/dev/null", O_WRONLY | O_APPEND, mode);
+	else
+		s->file.new_fd = safe_dup(fd);
+
 	if (s->file.new_fd < 0) {
 		local_errno = errno;
 
@@ -260,55 +285,55 @@ magic_setflags_wrapper(magic_t magic, int flags)
 }
 
 inline int
-magic_load_wrapper(magic_t magic, const char *magic_file, int flags)
+magic_load_wrapper(magic_t magic, const char *magic_file, int flags, int output)
 {
 	int rv;
-	MAGIC_FUNCTION(magic_load, rv, flags, magic, magic_file);
+	MAGIC_FUNCTION(magic_load, rv, flags, output, magic, magic_file);
 	return rv;
 }
 
 inline int
-magic_load_buffers_wrapper(magic_t magic, void **buffers, size_t *sizes, size_t count, int flags)
+magic_load_buffers_wrapper(magic_t magic, void **buffers, size_t *sizes, size_t count, int flags, int output)
 {
 	int rv;
-	MAGIC_FUNCTION(magic_load_buffers, rv, flags, magic, buffers, sizes, count);
+	MAGIC_FUNCTION(magic_load_buffers, rv, flags, output, magic, buffers, sizes, count);
 	return rv;
 }
 
 inline int
-magic_compile_wrapper(magic_t magic, const char *magic_file, int flags)
+magic_compile_wrapper(magic_t magic, const char *magic_file, int flags, int output)
 {
 	int rv;
-	MAGIC_FUNCTION(magic_compile, rv, flags, magic, magic_file);
+	MAGIC_FUNCTION(magic_compile, rv, flags, output, magic, magic_file);
 	return rv;
 }
 
 inline int
-magic_check_wrapper(magic_t magic, const char *magic_file, int flags)
+magic_check_wrapper(magic_t magic, const char *magic_file, int flags, int output)
 {
 	int rv;
-	MAGIC_FUNCTION(magic_check, rv, flags, magic, magic_file);
+	MAGIC_FUNCTION(magic_check, rv, flags, output, magic, magic_file);
 	return rv;
 }
 
 inline const char*
-magic_file_wrapper(magic_t magic, const char* filename, int flags)
+magic_file_wrapper(magic_t magic, const char* filename, int flags, int output)
 {
 	const char *cstring;
-	MAGIC_FUNCTION(magic_file, cstring, flags, magic, filename);
+	MAGIC_IDENTIFY_FUNCTION(magic_file, cstring, flags, output, magic, filename);
 	return cstring;
 }
 
 inline const char*
-magic_buffer_wrapper(magic_t magic, const void *buffer, size_t size, int flags)
+magic_buffer_wrapper(magic_t magic, const void *buffer, size_t size, int flags, int output)
 {
 	const char *cstring;
-	MAGIC_FUNCTION(magic_buffer, cstring, flags, magic, buffer, size);
+	MAGIC_IDENTIFY_FUNCTION(magic_buffer, cstring, flags, output, magic, buffer, size);
 	return cstring;
 }
 
 inline const char*
-magic_descriptor_wrapper(magic_t magic, int fd, int flags)
+magic_descriptor_wrapper(magic_t magic, int fd, int flags, int output)
 {
 	int local_errno;
 	const char *cstring;
@@ -318,7 +343,7 @@ magic_descriptor_wrapper(magic_t magic, int fd, int flags)
 		goto error;
 	}
 
-	MAGIC_FUNCTION(magic_descriptor, cstring, flags, magic, fd);
+	MAGIC_IDENTIFY_FUNCTION(magic_descriptor, cstring, flags, output, magic, fd);
 	return cstring;
 
 error:
@@ -326,6 +351,65 @@ error:
 	return NULL;
 }
 
+static void
+batch_result(magic_t magic, batch_t *batch, const char *cstring)
+{
+	batch->result = NULL;
+	batch->error = NULL;
+	batch->error_number = 0;
+
+	if (cstring != NULL) {
+		batch->result = strdup(cstring);
+		return;
+	}
+
+	cstring = magic_error(magic);
+	if (cstring != NULL)
+		batch->error = strdup(cstring);
+
+	batch->error_number = magic_errno(magic);
+}
+
+static int
+file_batch(magic_t magic, const char **filenames, size_t count, batch_t *batch)
+{
+	size_t i;
+
+	for (i = 0; i < count; i++)
+		batch_result(magic, &batch[i], magic_file(magic, filenames[i]));
+
+	return 0;
+}
+
+static int
+buffer_batch(magic_t magic, void **buffers, size_t *sizes, size_t count,
+	     batch_t *batch)
+{
+	size_t i;
+
+	for (i = 0; i < count; i++)
+		batch_result(magic, &batch[i],
+			     magic_buffer(magic, buffers[i], sizes[i]));
+
+	return 0;
+}
+
+inline int
+magic_file_batch_wrapper(magic_t magic, const char **filenames, size_t count, batch_t *batch, int flags, int output)
+{
+	int rv;
+	MAGIC_IDENTIFY_FUNCTION(file_batch, rv, flags, output, magic, filenames, count, batch);
+	return rv;
+}
+
+inline int
+magic_buffer_batch_wrapper(magic_t magic, void **buffers, size_t *sizes, size_t count, batch_t *batch, int flags, int output)
+{
+	int rv;
+	MAGIC_IDENTIFY_FUNCTION(buffer_batch, rv, flags, output, magic, buffers, sizes, count, batch);
+	return rv;
+}
+
 inline int
 magic_version_wrapper(void)
 {
@@ -7,16 +7,35 @@ extern "C" {
 
 #include "common.h"
 
-#define MAGIC_FUNCTION(f, r, x, ...)			 \
-	do {						 \
-		if ((x) & MAGIC_DEBUG)			 \
-			r = f(__VA_ARGS__);		 \
-		else {					 \
-			save_t __##f;			 \
-			override_error_output(&(__##f)); \
-			r = f(__VA_ARGS__);		 \
-			restore_error_output(&(__##f));  \
-		}					 \
+/*
+ * Redirecting the standard error output is process-wide, thus it is
+ * serialized so that concurrent calls do not clobber one another.
+ */
+#define MAGIC_FUNCTION(f, r, x, o, ...)				\
+	do {							\
+		if (((x) & MAGIC_DEBUG) && (o) < 0)		\
+			r = f(__VA_ARGS__);			\
+		else {						\
+			save_t __##f;				\
+			lock_error_output();			\
+			override_error_output(&(__##f), (o));	\
+			r = f(__VA_ARGS__);			\
+			restore_error_output(&(__##f));		\
+			unlock_error_output();			\
+		}						\
+	} while(0)
+
+/*
+ * When identifying files, the Magic library only ever writes to the
+ * standard error output with either the DEBUG or CHECK flag set, thus
+ * the output is otherwise not redirected, unless it is to be captured.
+ */
+#define MAGIC_IDENTIFY_FUNCTION(f, r, x, o, ...)		\
+	do {							\
+		if ((o) < 0 && !((x) & MAGIC_CHECK))		\
+			r = f(__VA_ARGS__);			\
+		else						\
+			MAGIC_FUNCTION(f, r, x, o, __VA_ARGS__);\
 	} while(0)
 
 typedef struct file_data {
@@ -30,6 +49,21 @@ typedef struct save {
 	int status;
 } save_t;
 
+/*
+ * The result for a single item of a batch, with the strings copied,
+ * as the Magic library reuses its buffers between calls.
+ */
+typedef struct batch {
+	char *result;
+	char *error;
+	int error_number;
+} batch_t;
+
+extern void lock_error_output(void);
+extern void unlock_error_output(void);
+extern int override_error_output(void *data, int fd);
+extern int restore_error_output(void *data);
+
 extern magic_t magic_open_wrapper(int flags);
 extern void magic_close_wrapper(magic_t magic);
 
@@ -45,18 +79,30 @@ extern int magic_setparam_wrapper(magic_t magic, int parameter,
 extern int magic_getflags_wrapper(magic_t magic);
 extern int magic_setflags_wrapper(magic_t magic, int flags);
 
-extern int magic_load_wrapper(magic_t magic, const char *magic_file, int flags);
+extern int magic_load_wrapper(magic_t magic, const char *magic_file, int flags,
+			      int output);
 extern int magic_load_buffers_wrapper(magic_t magic, void **buffers,
-				      size_t *sizes, size_t count, int flags);
+				      size_t *sizes, size_t count, int flags,
+				      int output);
 
-extern int magic_compile_wrapper(magic_t magic, const char *magic_file, int flags);
-extern int magic_check_wrapper(magic_t magic, const char *magic_file, int flags);
+extern int magic_compile_wrapper(magic_t magic, const char *magic_file,
+				 int flags, int output);
+extern int magic_check_wrapper(magic_t magic, const char *magic_file,
+			       int flags, int output);
 
 extern const char* magic_file_wrapper(magic_t magic, const char *filename,
-				      int flags);
+				      int flags, int output);
 extern const char* magic_buffer_wrapper(magic_t magic, const void *buffer,
-					size_t size, int flags);
-extern const char* magic_descriptor_wrapper(magic_t magic, int fd, int flags);
+					size_t size, int flags, int output);
+extern const char* magic_descriptor_wrapper(magic_t magic, int fd, int flags,
+					    int output);
+
+extern int magic_file_batch_wrapper(magic_t magic, const char **filenames,
+				    size_t count, batch_t *batch, int flags,
+				    int output);
+extern int magic_buffer_batch_wrapper(magic_t magic, void **buffers,
+				      size_t *sizes, size_t count,
+				      batch_t *batch, int flags, int output);
 
 extern int magic_version_wrapper(void);
 
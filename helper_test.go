@@ -20,6 +20,12 @@ var (
 
 	// Magic file for testing only ...
 	shellMagicFile = path.Clean(path.Join(fixturesDirectory, "shell.magic"))
+
+	// Magic file that is valid, but has a continuation level jump ...
+	warningMagicFile = path.Clean(path.Join(fixturesDirectory, "png-warning.magic"))
+
+	// Magic file that is not valid ...
+	brokenMagicFile = path.Clean(path.Join(fixturesDirectory, "png-broken.magic"))
 )
 
 func compareStrings(this, other string) bool {
@@ -0,0 +1,354 @@
+/*
+Package httpmagic implements a middleware for the net/http package that
+validates the content of request bodies, such as uploads, using the Magic
+library rather than trusting the Content-Type header sent by the client.
+
+The body of each request is identified, and so is each part of a body of
+the "multipart/form-data" type, and the request is rejected with the 415
+(Unsupported Media Type) status code should the MIME type detected not be
+allowed. Otherwise, the body is passed on to the next handler unchanged.
+*/
+package httpmagic
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"io/ioutil"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/kwilczynski/go-magic"
+)
+
+// The default amount of the body of the "multipart/form-data" type
+// kept in memory, as per the ParseMultipartForm function of the
+// http.Request type.
+const defaultMaxMemory = 32 << 20
+
+// Options represents options that control the middleware.
+type Options struct {
+	// The MIME types allowed, e.g., "image/png", where either
+	// the subtype or both the type and the subtype can be "*",
+	// e.g., "image/*", to match any. Nothing is allowed if empty.
+	Allow []string
+	// Set the Content-Type header of the request to the MIME type
+	// detected. The header is never set for a body of the type
+	// "multipart/form-data", as the boundary has to be retained.
+	RewriteContentType bool
+	// The amount of the body of the "multipart/form-data" type kept
+	// in memory while its parts are identified, beyond which the body
+	// is kept in a temporary file instead, defaults to 32 MB.
+	MaxMemory int64
+	// The largest size of the body permitted, beyond which the request
+	// is rejected, or no limit if not positive. Setting a limit is
+	// recommended, as the body of the "multipart/form-data" type is
+	// kept in full, either in memory or in a temporary file, while its
+	// parts are identified.
+	MaxBodySize int64
+}
+
+// Middleware returns a middleware that validates the content of request
+// bodies, as per Handler.
+func Middleware(p *magic.Pool, opts Options) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return Handler(next, p, opts)
+	}
+}
+
+// Handler returns a handler that identifies the body of each request
+// using the Magic objects from the pool, and calls the next handler
+// only if the MIME type detected is allowed, replaying the body in full.
+//
+// Each part of a body of the "multipart/form-data" type that is a file,
+// that is a part with either a file name or its own Content-Type header,
+// is identified separately, and all of these have to be allowed, whereas
+// form values are not identified. The body is read in full beforehand.
+//
+// Otherwise, only as much of the body as the PARAM_BYTES_MAX parameter
+// permits is read beforehand, and the body is then streamed as usual.
+//
+// The request is rejected with the 415 (Unsupported Media Type) status
+// code should the MIME type detected not be allowed, with the 413 (Request
+// Entity Too Large) status code should the body exceed the size permitted,
+// with the 400 (Bad Request) status code should the body be malformed or
+// could not be read, or with the 500 (Internal Server Error) status code
+// should the identification fail. Should the body streamed to the next
+// handler exceed the size permitted, then reading it fails instead.
+func Handler(next http.Handler, p *magic.Pool, opts Options) http.Handler {
+	if opts.MaxMemory <= 0 {
+		opts.MaxMemory = defaultMaxMemory
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Body == nil || r.Body == http.NoBody {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		var (
+			body        io.ReadCloser = r.Body
+			contentType string
+			limit       *limitReader
+			err         error
+		)
+
+		if opts.MaxBodySize > 0 {
+			if r.ContentLength > opts.MaxBodySize {
+				r.Body.Close()
+				writeError(w, errTooLarge)
+				return
+			}
+			limit = &limitReader{r: r.Body, n: opts.MaxBodySize}
+			body = &readCloser{limit, r.Body.Close}
+		}
+
+		mediaType, parameters, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+		if mediaType == "multipart/form-data" {
+			body, err = checkMultipart(body, parameters["boundary"], p, opts)
+		} else {
+			body, contentType, err = check(body, p, opts)
+		}
+		if err != nil {
+			if limit != nil && limit.err != nil {
+				err = errTooLarge
+			}
+			r.Body.Close()
+			writeError(w, err)
+			return
+		}
+		defer body.Close()
+
+		r.Body = body
+		if opts.RewriteContentType && contentType != "" {
+			r.Header.Set("Content-Type", contentType)
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// Errors that determine the status code of the response.
+var (
+	errBadRequest  = errors.New("malformed request body")
+	errTooLarge    = errors.New("request body too large")
+	errUnsupported = errors.New("unsupported media type")
+)
+
+// writeError replies to the request with the status code that
+// corresponds to the error.
+func writeError(w http.ResponseWriter, err error) {
+	code := http.StatusInternalServerError
+	switch err {
+	case errBadRequest:
+		code = http.StatusBadRequest
+	case errTooLarge:
+		code = http.StatusRequestEntityTooLarge
+	case errUnsupported:
+		code = http.StatusUnsupportedMediaType
+	}
+	http.Error(w, http.StatusText(code), code)
+}
+
+// check identifies the body and returns a body that replays it in full,
+// together with the MIME type detected, including its parameters.
+func check(body io.ReadCloser, p *magic.Pool, opts Options) (io.ReadCloser, string, error) {
+	result, r, err := p.Sniff(body)
+	if err != nil {
+		if _, ok := err.(*magic.Error); ok {
+			return nil, "", err
+		}
+		return nil, "", errBadRequest
+	}
+	if !allowed(opts.Allow, result.MIMEType) {
+		return nil, "", errUnsupported
+	}
+	return &readCloser{r, body.Close}, contentType(result), nil
+}
+
+// checkMultipart identifies each part of the body of the "multipart/form-data"
+// type that is a file, and returns a body that replays it in full.
+func checkMultipart(body io.ReadCloser, boundary string, p *magic.Pool, opts Options) (io.ReadCloser, error) {
+	if boundary == "" {
+		return nil, errBadRequest
+	}
+
+	s := &spool{max: opts.MaxMemory}
+	mr := multipart.NewReader(io.TeeReader(body, s), boundary)
+
+	err := func() error {
+		for {
+			part, err := mr.NextPart()
+			if err == io.EOF {
+				return nil
+			}
+			if err != nil {
+				if s.err != nil {
+					return s.err
+				}
+				return errBadRequest
+			}
+			if part.FileName() != "" || part.Header.Get("Content-Type") != "" {
+				result, _, err := p.Sniff(part)
+				if err != nil {
+					if _, ok := err.(*magic.Error); ok {
+						return err
+					}
+					return errBadRequest
+				}
+				if !allowed(opts.Allow, result.MIMEType) {
+					return errUnsupported
+				}
+			}
+			// Make sure that the whole part is read, so that
+			// it is kept in full to be replayed later.
+			if _, err := io.Copy(ioutil.Discard, part); err != nil {
+				return errBadRequest
+			}
+		}
+	}()
+	if err != nil {
+		s.Close()
+		return nil, err
+	}
+
+	// Anything that follows the closing boundary, if any, is not
+	// read by the multipart reader, and is replayed from the body.
+	r, err := s.reader()
+	if err != nil {
+		s.Close()
+		return nil, err
+	}
+	return &readCloser{io.MultiReader(r, body), func() error {
+		s.Close()
+		return body.Close()
+	}}, nil
+}
+
+// allowed returns true if the MIME type matches any of the patterns.
+func allowed(patterns []string, mediaType string) bool {
+	mediaType = strings.ToLower(mediaType)
+	for _, pattern := range patterns {
+		pattern = strings.ToLower(strings.TrimSpace(pattern))
+		switch {
+		case pattern == "*/*" || pattern == mediaType:
+			return true
+		case strings.HasSuffix(pattern, "/*"):
+			if strings.HasPrefix(mediaType, pattern[:len(pattern)-1]) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// contentType returns the MIME type with its parameters, omitting
+// the "binary" charset, which is not meaningful as a header value.
+func contentType(r magic.Result) string {
+	if r.MIMEType == "" {
+		return ""
+	}
+	parameters := make(map[string]string)
+	for k, v := range r.Parameters {
+		if k == "charset" && v == "binary" {
+			continue
+		}
+		parameters[k] = v
+	}
+	return mime.FormatMediaType(r.MIMEType, parameters)
+}
+
+// readCloser combines a reader with the function closing it.
+type readCloser struct {
+	io.Reader
+	close func() error
+}
+
+// Close calls the function closing the reader.
+func (r *readCloser) Close() error {
+	return r.close()
+}
+
+// limitReader reads from the reader until more than the limit is read,
+// after which it fails with the errTooLarge error.
+type limitReader struct {
+	r   io.Reader
+	n   int64
+	err error
+}
+
+// Read reads from the reader, at most one byte past the limit, so that
+// a body of exactly the size permitted is read in full.
+func (l *limitReader) Read(b []byte) (int, error) {
+	if l.err != nil {
+		return 0, l.err
+	}
+	if int64(len(b)) > l.n+1 {
+		b = b[:l.n+1]
+	}
+	n, err := l.r.Read(b)
+	if int64(n) > l.n {
+		n, l.n, l.err = int(l.n), 0, errTooLarge
+		return n, l.err
+	}
+	l.n -= int64(n)
+	return n, err
+}
+
+// spool keeps what is written to it in memory, up to the maximum size,
+// beyond which everything written is kept in a temporary file instead.
+type spool struct {
+	max    int64
+	buffer bytes.Buffer
+	file   *os.File
+	err    error
+}
+
+// Write writes to either the buffer or the temporary file.
+func (s *spool) Write(b []byte) (int, error) {
+	if s.err != nil {
+		return 0, s.err
+	}
+	if s.file == nil && int64(s.buffer.Len()+len(b)) > s.max {
+		f, err := ioutil.TempFile("", "httpmagic-")
+		if err != nil {
+			s.err = err
+			return 0, err
+		}
+		s.file = f
+		if _, err := s.buffer.WriteTo(f); err != nil {
+			s.err = err
+			return 0, err
+		}
+	}
+	if s.file != nil {
+		n, err := s.file.Write(b)
+		if err != nil {
+			s.err = err
+		}
+		return n, err
+	}
+	return s.buffer.Write(b)
+}
+
+// reader returns a reader that yields everything written so far.
+func (s *spool) reader() (io.Reader, error) {
+	if s.file == nil {
+		return &s.buffer, nil
+	}
+	if _, err := s.file.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+	return s.file, nil
+}
+
+// Close removes the temporary file, if any.
+func (s *spool) Close() error {
+	if s.file == nil {
+		return nil
+	}
+	s.file.Close()
+	return os.Remove(s.file.Name())
+}
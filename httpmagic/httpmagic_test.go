@@ -0,0 +1,318 @@
+package httpmagic
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"path"
+	"testing"
+
+	"github.com/kwilczynski/go-magic"
+)
+
+// PNG image data, 1634 x 2224, 8-bit/color RGBA, non-interlaced
+var sampleImageFile = path.Clean(path.Join("..", "test", "fixtures", "gopher.png"))
+
+var sampleScript = []byte("#!/bin/bash\n\necho 'Hello, World!'\n")
+
+// recorder is the next handler that records the request body.
+type recorder struct {
+	called      bool
+	body        []byte
+	contentType string
+}
+
+func (h *recorder) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.called = true
+	h.body, _ = ioutil.ReadAll(r.Body)
+	h.contentType = r.Header.Get("Content-Type")
+}
+
+func newPool(t *testing.T) *magic.Pool {
+	p, err := magic.NewPool(2)
+	if err != nil {
+		t.Fatalf("unable to create new Pool type: %s", err.Error())
+	}
+	return p
+}
+
+func readImage(t *testing.T) []byte {
+	b, err := ioutil.ReadFile(sampleImageFile)
+	if err != nil {
+		t.Fatalf("unable to read file %q: %s", sampleImageFile, err.Error())
+	}
+	return b
+}
+
+type part struct {
+	field    string
+	fileName string
+	content  []byte
+}
+
+func newMultipart(t *testing.T, parts ...part) ([]byte, string) {
+	var buffer bytes.Buffer
+
+	w := multipart.NewWriter(&buffer)
+	for _, p := range parts {
+		var (
+			pw  io.Writer
+			err error
+		)
+		if p.fileName != "" {
+			pw, err = w.CreateFormFile(p.field, p.fileName)
+		} else {
+			pw, err = w.CreateFormField(p.field)
+		}
+		if err != nil {
+			t.Fatalf("unable to create part: %s", err.Error())
+		}
+		pw.Write(p.content)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("unable to close multipart writer: %s", err.Error())
+	}
+	return buffer.Bytes(), w.FormDataContentType()
+}
+
+func serve(h http.Handler, body []byte, contentType string) *httptest.ResponseRecorder {
+	r := httptest.NewRequest(http.MethodPost, "/upload", bytes.NewReader(body))
+	if contentType != "" {
+		r.Header.Set("Content-Type", contentType)
+	}
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	return w
+}
+
+func TestHandler(t *testing.T) {
+	p := newPool(t)
+	defer p.Close()
+
+	image := readImage(t)
+
+	var handlerTests = []struct {
+		body        []byte
+		contentType string
+		rewrite     bool
+		code        int
+		want        string
+	}{
+		{image, "application/octet-stream", false, http.StatusOK, "application/octet-stream"},
+		{image, "application/octet-stream", true, http.StatusOK, "image/png"},
+		{image, "", true, http.StatusOK, "image/png"},
+		{sampleScript, "image/png", false, http.StatusUnsupportedMediaType, ""},
+		{sampleScript, "image/png", true, http.StatusUnsupportedMediaType, ""},
+		{[]byte{}, "image/png", false, http.StatusUnsupportedMediaType, ""},
+	}
+
+	for _, tt := range handlerTests {
+		next := &recorder{}
+		h := Handler(next, p, Options{
+			Allow:              []string{"image/*"},
+			RewriteContentType: tt.rewrite,
+		})
+
+		w := serve(h, tt.body, tt.contentType)
+		if w.Code != tt.code {
+			t.Errorf("value given %d, want %d", w.Code, tt.code)
+		}
+		if next.called != (tt.code == http.StatusOK) {
+			t.Errorf("value given %v, want %v", next.called, tt.code == http.StatusOK)
+		}
+		if !next.called {
+			continue
+		}
+		if !bytes.Equal(next.body, tt.body) {
+			t.Errorf("value given %d bytes, want %d bytes", len(next.body), len(tt.body))
+		}
+		if next.contentType != tt.want {
+			t.Errorf("value given %q, want %q", next.contentType, tt.want)
+		}
+	}
+}
+
+func TestHandler_NoBody(t *testing.T) {
+	p := newPool(t)
+	defer p.Close()
+
+	next := &recorder{}
+	h := Handler(next, p, Options{})
+
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+
+	if w.Code != http.StatusOK || !next.called {
+		t.Errorf("value given %d %v, want %d %v", w.Code, next.called, http.StatusOK, true)
+	}
+}
+
+func TestHandler_Multipart(t *testing.T) {
+	p := newPool(t)
+	defer p.Close()
+
+	image := readImage(t)
+
+	var multipartTests = []struct {
+		parts     []part
+		maxMemory int64
+		code      int
+	}{
+		{[]part{{"image", "gopher.png", image}, {"name", "", []byte("gopher")}}, 0, http.StatusOK},
+		{[]part{{"image", "gopher.png", image}, {"name", "", []byte("gopher")}}, 1024, http.StatusOK},
+		{[]part{{"name", "", sampleScript}}, 0, http.StatusOK},
+		{[]part{{"image", "gopher.png", image}, {"script", "gopher.png", sampleScript}}, 0, http.StatusUnsupportedMediaType},
+		{[]part{{"script", "script.sh", sampleScript}}, 1024, http.StatusUnsupportedMediaType},
+	}
+
+	for _, tt := range multipartTests {
+		next := &recorder{}
+		h := Handler(next, p, Options{
+			Allow:              []string{"image/png"},
+			RewriteContentType: true,
+			MaxMemory:          tt.maxMemory,
+		})
+
+		body, contentType := newMultipart(t, tt.parts...)
+
+		w := serve(h, body, contentType)
+		if w.Code != tt.code {
+			t.Errorf("value given %d, want %d", w.Code, tt.code)
+		}
+		if next.called != (tt.code == http.StatusOK) {
+			t.Errorf("value given %v, want %v", next.called, tt.code == http.StatusOK)
+		}
+		if !next.called {
+			continue
+		}
+		if !bytes.Equal(next.body, body) {
+			t.Errorf("value given %d bytes, want %d bytes", len(next.body), len(body))
+		}
+		// The boundary has to be retained.
+		if next.contentType != contentType {
+			t.Errorf("value given %q, want %q", next.contentType, contentType)
+		}
+	}
+}
+
+func TestHandler_Malformed(t *testing.T) {
+	p := newPool(t)
+	defer p.Close()
+
+	next := &recorder{}
+	h := Handler(next, p, Options{Allow: []string{"*/*"}})
+
+	var malformedTests = []struct {
+		body        string
+		contentType string
+	}{
+		{"data", "multipart/form-data"},
+		{"data", "multipart/form-data; boundary=xyz"},
+		{"--xyz\r\nContent-Disposition: form-data; name=\"a\"\r\n\r\ndata", "multipart/form-data; boundary=xyz"},
+	}
+
+	for _, tt := range malformedTests {
+		w := serve(h, []byte(tt.body), tt.contentType)
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("value given %d, want %d", w.Code, http.StatusBadRequest)
+		}
+	}
+	if next.called {
+		t.Errorf("value given %v, want %v", next.called, false)
+	}
+}
+
+func TestHandler_MaxBodySize(t *testing.T) {
+	p := newPool(t)
+	defer p.Close()
+
+	image := readImage(t)
+	body, contentType := newMultipart(t, part{"image", "gopher.png", image})
+
+	var maxBodySizeTests = []struct {
+		body          []byte
+		contentType   string
+		contentLength bool
+		maxBodySize   int64
+		code          int
+	}{
+		{image, "", true, 1024, http.StatusRequestEntityTooLarge},
+		{image, "", false, 1024, http.StatusRequestEntityTooLarge},
+		{body, contentType, true, 1024, http.StatusRequestEntityTooLarge},
+		{body, contentType, false, 1024, http.StatusRequestEntityTooLarge},
+		{body, contentType, false, int64(len(body)) - 1, http.StatusRequestEntityTooLarge},
+		{body, contentType, false, int64(len(body)), http.StatusOK},
+		{image, "", false, int64(len(image)), http.StatusOK},
+		{body, contentType, false, 0, http.StatusOK},
+	}
+
+	for _, tt := range maxBodySizeTests {
+		next := &recorder{}
+		h := Handler(next, p, Options{
+			Allow:       []string{"image/png"},
+			MaxBodySize: tt.maxBodySize,
+		})
+
+		r := httptest.NewRequest(http.MethodPost, "/upload", bytes.NewReader(tt.body))
+		if tt.contentType != "" {
+			r.Header.Set("Content-Type", tt.contentType)
+		}
+		// Without the length known upfront, the body has
+		// to be read before it is found to be too large.
+		if !tt.contentLength {
+			r.ContentLength = -1
+		}
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+
+		if w.Code != tt.code {
+			t.Errorf("value given %d, want %d", w.Code, tt.code)
+		}
+		if next.called != (tt.code == http.StatusOK) {
+			t.Errorf("value given %v, want %v", next.called, tt.code == http.StatusOK)
+		}
+		if next.called && !bytes.Equal(next.body, tt.body) {
+			t.Errorf("value given %d bytes, want %d bytes", len(next.body), len(tt.body))
+		}
+	}
+}
+
+func TestMiddleware(t *testing.T) {
+	p := newPool(t)
+	defer p.Close()
+
+	next := &recorder{}
+	h := Middleware(p, Options{Allow: []string{"text/*"}})(next)
+
+	w := serve(h, sampleScript, "text/plain")
+	if w.Code != http.StatusOK || !next.called {
+		t.Errorf("value given %d %v, want %d %v", w.Code, next.called, http.StatusOK, true)
+	}
+}
+
+func TestAllowed(t *testing.T) {
+	var allowedTests = []struct {
+		patterns  []string
+		mediaType string
+		allowed   bool
+	}{
+		{nil, "image/png", false},
+		{[]string{"image/png"}, "image/png", true},
+		{[]string{"Image/PNG"}, "image/png", true},
+		{[]string{"image/jpeg"}, "image/png", false},
+		{[]string{"image/*"}, "image/png", true},
+		{[]string{"image/*"}, "imagex/png", false},
+		{[]string{"text/*", "image/*"}, "image/png", true},
+		{[]string{"*/*"}, "application/x-empty", true},
+	}
+
+	for _, tt := range allowedTests {
+		if v := allowed(tt.patterns, tt.mediaType); v != tt.allowed {
+			t.Errorf("value given %v, want %v for %q in %v", v, tt.allowed, tt.mediaType, tt.patterns)
+		}
+	}
+}
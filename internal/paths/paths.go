@@ -0,0 +1,33 @@
+// Package paths implements the handling of the paths given to the
+// commands, which can name either files or directories.
+package paths
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+)
+
+// Expand returns the file, or every regular file within the directory,
+// sorted by name, without descending into any of its subdirectories.
+func Expand(path string) ([]string, error) {
+	fi, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	if !fi.IsDir() {
+		return []string{path}, nil
+	}
+
+	entries, err := ioutil.ReadDir(path)
+	if err != nil {
+		return nil, err
+	}
+	var files []string
+	for _, entry := range entries {
+		if entry.Mode().IsRegular() {
+			files = append(files, filepath.Join(path, entry.Name()))
+		}
+	}
+	return files, nil
+}
@@ -0,0 +1,45 @@
+package paths
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestExpand(t *testing.T) {
+	dir := t.TempDir()
+
+	for _, name := range []string{"b.magic", "a.magic"} {
+		if err := ioutil.WriteFile(filepath.Join(dir, name), nil, 0644); err != nil {
+			t.Fatalf("unable to write file %q: %s", name, err.Error())
+		}
+	}
+	if err := os.Mkdir(filepath.Join(dir, "c"), 0755); err != nil {
+		t.Fatalf("unable to create directory: %s", err.Error())
+	}
+
+	var expandTests = []struct {
+		given    string
+		expected []string
+	}{
+		{dir, []string{filepath.Join(dir, "a.magic"), filepath.Join(dir, "b.magic")}},
+		{filepath.Join(dir, "b.magic"), []string{filepath.Join(dir, "b.magic")}},
+		{filepath.Join(dir, "c"), nil},
+	}
+
+	for _, tt := range expandTests {
+		v, err := Expand(tt.given)
+		if err != nil {
+			t.Fatalf("value given {%v}, want {%v}", err, nil)
+		}
+		if !reflect.DeepEqual(v, tt.expected) {
+			t.Errorf("value given %q, want %q", v, tt.expected)
+		}
+	}
+
+	if _, err := Expand(filepath.Join(dir, "does-not-exist")); !os.IsNotExist(err) {
+		t.Errorf("value given {%v}, want a not exist error", err)
+	}
+}
@@ -0,0 +1,91 @@
+package magic
+
+import (
+	"encoding/json"
+	"io"
+)
+
+// resultJSON represents the Result type as encoded into JSON, alongside
+// the path of the file and the error, if any, as per the Encoder type.
+type resultJSON struct {
+	Path         string            `json:"path,omitempty"`
+	Description  string            `json:"description,omitempty"`
+	MIMEType     string            `json:"mime_type,omitempty"`
+	Encoding     string            `json:"encoding,omitempty"`
+	Parameters   map[string]string `json:"parameters,omitempty"`
+	Extensions   []string          `json:"extensions,omitempty"`
+	AppleCreator string            `json:"apple_creator,omitempty"`
+	AppleType    string            `json:"apple_type,omitempty"`
+	Matches      []string          `json:"matches,omitempty"`
+	Error        string            `json:"error,omitempty"`
+}
+
+func newResultJSON(r Result) *resultJSON {
+	return &resultJSON{
+		Description:  r.Description,
+		MIMEType:     r.MIMEType,
+		Encoding:     r.Encoding(),
+		Parameters:   r.Parameters,
+		Extensions:   r.Extensions,
+		AppleCreator: r.AppleCreator,
+		AppleType:    r.AppleType,
+		Matches:      r.Matches,
+	}
+}
+
+// MarshalJSON returns the JSON encoding of the Result type, with the
+// MIME encoding (the "charset" parameter) included separately for
+// convenience, and any empty fields omitted.
+func (r Result) MarshalJSON() ([]byte, error) {
+	return json.Marshal(newResultJSON(r))
+}
+
+// UnmarshalJSON parses the JSON encoding of the Result type, as per
+// the MarshalJSON function.
+func (r *Result) UnmarshalJSON(b []byte) error {
+	var v resultJSON
+	if err := json.Unmarshal(b, &v); err != nil {
+		return err
+	}
+	*r = Result{
+		Description:  v.Description,
+		MIMEType:     v.MIMEType,
+		Parameters:   v.Parameters,
+		Extensions:   v.Extensions,
+		AppleCreator: v.AppleCreator,
+		AppleType:    v.AppleType,
+		Matches:      v.Matches,
+	}
+	if v.Encoding != "" && r.Encoding() == "" {
+		if r.Parameters == nil {
+			r.Parameters = make(map[string]string)
+		}
+		r.Parameters["charset"] = v.Encoding
+	}
+	return nil
+}
+
+// Encoder writes the results of identification, one JSON object per
+// line, also known as NDJSON, to a writer.
+type Encoder struct {
+	encoder *json.Encoder
+}
+
+// NewEncoder returns a new encoder that writes to the writer.
+func NewEncoder(w io.Writer) *Encoder {
+	encoder := json.NewEncoder(w)
+	encoder.SetEscapeHTML(false)
+	return &Encoder{encoder}
+}
+
+// Encode writes the result for the file of the given path, as per the
+// MarshalJSON function of the Result type, together with the path and
+// the error, if any, followed by a newline.
+func (e *Encoder) Encode(path string, r Result, err error) error {
+	v := newResultJSON(r)
+	v.Path = path
+	if err != nil {
+		v.Error = err.Error()
+	}
+	return e.encoder.Encode(v)
+}
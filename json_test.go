@@ -0,0 +1,73 @@
+package magic
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestResult_MarshalJSON(t *testing.T) {
+	mgc, err := New()
+	if err != nil {
+		t.Fatalf("unable to create new Magic type: %s", err.Error())
+	}
+	defer mgc.Close()
+
+	rv, err := mgc.FileResult(sampleImageFile)
+	if err != nil {
+		t.Fatalf("value given {%v}, want {%v}", err, nil)
+	}
+
+	b, err := json.Marshal(rv)
+	if err != nil {
+		t.Fatalf("value given {%v}, want {%v}", err, nil)
+	}
+
+	var m map[string]interface{}
+	if err := json.Unmarshal(b, &m); err != nil {
+		t.Fatalf("value given {%v}, want {%v}", err, nil)
+	}
+	for k, v := range map[string]interface{}{
+		"description": rv.Description,
+		"mime_type":   "image/png",
+		"encoding":    "binary",
+		"extensions":  []interface{}{"png"},
+	} {
+		if !reflect.DeepEqual(m[k], v) {
+			t.Errorf("value given %v, want %v for %q", m[k], v, k)
+		}
+	}
+	if _, ok := m["apple_creator"]; ok {
+		t.Errorf("value given %v, want none", m["apple_creator"])
+	}
+
+	var r Result
+	if err := json.Unmarshal(b, &r); err != nil {
+		t.Fatalf("value given {%v}, want {%v}", err, nil)
+	}
+	if !reflect.DeepEqual(r, rv) {
+		t.Errorf("value given %+v, want %+v", r, rv)
+	}
+
+	if err := json.Unmarshal([]byte(`{"mime_type":"text/plain","encoding":"utf-8"}`), &r); err != nil {
+		t.Fatalf("value given {%v}, want {%v}", err, nil)
+	}
+	if v := "utf-8"; r.Encoding() != v {
+		t.Errorf("value given %q, want %q", r.Encoding(), v)
+	}
+}
+
+func TestEncoder(t *testing.T) {
+	var buffer bytes.Buffer
+
+	e := NewEncoder(&buffer)
+	e.Encode("a.txt", Result{Description: "ASCII text", MIMEType: "text/plain", Parameters: map[string]string{"charset": "us-ascii"}}, nil)
+	e.Encode("b.txt", Result{}, &Error{2, "No such file or directory"})
+
+	v := `{"path":"a.txt","description":"ASCII text","mime_type":"text/plain","encoding":"us-ascii","parameters":{"charset":"us-ascii"}}` + "\n" +
+		`{"path":"b.txt","error":"magic: No such file or directory"}` + "\n"
+	if buffer.String() != v {
+		t.Errorf("value given %q, want %q", buffer.String(), v)
+	}
+}
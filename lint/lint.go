@@ -0,0 +1,255 @@
+/*
+Package lint implements a linter for the source Magic files, as described
+in the magic(5) manual page, which reports issues that the Magic library
+does not consider to be errors, but that are likely mistakes, such as
+duplicate patterns, or entries without a MIME type.
+*/
+package lint
+
+import (
+	"fmt"
+	"mime"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/kwilczynski/go-magic/parse"
+)
+
+// Severity represents the severity of an issue.
+type Severity string
+
+const (
+	// An issue that is likely a mistake.
+	SeverityWarning Severity = "warning"
+
+	// An issue that prevents the source Magic file from being used.
+	SeverityError Severity = "error"
+)
+
+// Names of the checks, as reported for each issue.
+const (
+	CheckSyntax      = "syntax"       // The source Magic file is not valid.
+	CheckDuplicate   = "duplicate"    // The pattern is shadowed by another one.
+	CheckMissingMIME = "missing-mime" // There is no MIME type.
+	CheckInvalidMIME = "invalid-mime" // The MIME type is not valid.
+	CheckUnreachable = "unreachable"  // The entry can never match.
+	CheckStrength    = "strength"     // The strength adjustment is suspicious.
+)
+
+// Issue represents a single issue found in the source Magic file.
+type Issue struct {
+	File     string   `json:"file"`     // The name of the file.
+	Line     int      `json:"line"`     // The line number, starting at 1.
+	Check    string   `json:"check"`    // The name of the check, e.g., "duplicate".
+	Severity Severity `json:"severity"` // The severity of the issue.
+	Message  string   `json:"message"`  // The actual message.
+}
+
+// String returns a string representation of the Issue type in the
+// "file:line: severity: message (check)" format.
+func (i Issue) String() string {
+	return fmt.Sprintf("%s:%d: %s: %s (%s)", i.File, i.Line, i.Severity, i.Message, i.Check)
+}
+
+// Files parses and lints the named source Magic files, as per Lint.
+//
+// Any syntax errors are reported as issues, and the remaining lines
+// of the source Magic file are linted regardless.
+func Files(names ...string) ([]Issue, error) {
+	var (
+		files  []*parse.File
+		issues []Issue
+	)
+
+	for _, name := range names {
+		f, err := os.Open(name)
+		if err != nil {
+			return nil, err
+		}
+		file, err := parse.Parse(name, f)
+		f.Close()
+		if errors, ok := err.(parse.ErrorList); ok {
+			for _, e := range errors {
+				issues = append(issues, Issue{name, e.Line, CheckSyntax, SeverityError, e.Msg})
+			}
+		} else if err != nil {
+			return nil, err
+		}
+		files = append(files, file)
+	}
+	return sortIssues(append(issues, Lint(files...)...), names), nil
+}
+
+// Lint lints the source Magic files, and returns the issues found, sorted
+// by the file, in the order given, and the line number.
+//
+// The files are linted together, so that patterns within one file that
+// are shadowed by the patterns within another file are reported.
+func Lint(files ...*parse.File) []Issue {
+	var names []string
+
+	l := &linter{seen: make(map[string]position)}
+	for _, f := range files {
+		l.file = f.Name
+		for _, e := range f.Entries {
+			l.lintEntry(e)
+		}
+		names = append(names, f.Name)
+	}
+	return sortIssues(l.issues, names)
+}
+
+type position struct {
+	file string
+	line int
+}
+
+type linter struct {
+	file   string
+	issues []Issue
+	// Top-level patterns seen so far, and where.
+	seen map[string]position
+}
+
+func (l *linter) report(line int, check string, format string, args ...interface{}) {
+	l.issues = append(l.issues, Issue{l.file, line, check, SeverityWarning, fmt.Sprintf(format, args...)})
+}
+
+func (l *linter) lintEntry(e *parse.Entry) {
+	// Named entries are used by other entries, and thus are neither
+	// matched on their own, nor do these need a MIME type.
+	if e.TypeName() == "name" {
+		l.lintChildren(e)
+		return
+	}
+
+	key := e.Offset + "\x00" + e.Type + "\x00" + e.Test
+	if p, ok := l.seen[key]; ok {
+		l.report(e.Line, CheckDuplicate, "pattern is the same as at %s:%d, and will never be reached", p.file, p.line)
+	} else {
+		l.seen[key] = position{l.file, e.Line}
+	}
+
+	if !hasMIME(e) {
+		l.report(e.Line, CheckMissingMIME, "entry has no MIME type")
+	}
+
+	l.lintDirectives(e)
+	l.lintTest(e)
+	l.lintChildren(e)
+}
+
+func (l *linter) lintChildren(parent *parse.Entry) {
+	for _, e := range parent.Children {
+		if e.Level > parent.Level+1 {
+			l.report(e.Line, CheckUnreachable, "continuation level %d follows level %d, and will never be reached", e.Level, parent.Level)
+		}
+		l.lintDirectives(e)
+		l.lintTest(e)
+		l.lintChildren(e)
+	}
+}
+
+func (l *linter) lintDirectives(e *parse.Entry) {
+	for _, d := range e.Directives {
+		switch d.Name {
+		case "mime":
+			if _, _, err := mime.ParseMediaType(d.Value); err != nil || !strings.Contains(d.Value, "/") {
+				l.report(d.Line, CheckInvalidMIME, "MIME type %q is not valid", d.Value)
+			}
+		case "strength":
+			if e.Level > 0 {
+				l.report(d.Line, CheckStrength, "strength applies to the top-level entry, not the continuation entry")
+			}
+			l.lintStrength(d)
+		}
+	}
+}
+
+func (l *linter) lintStrength(d *parse.Directive) {
+	s := strings.TrimSpace(d.Value)
+	if s == "" || !strings.ContainsRune("+-*/", rune(s[0])) {
+		l.report(d.Line, CheckStrength, "strength %q is not valid", d.Value)
+		return
+	}
+	op := s[0]
+	v, err := strconv.Atoi(strings.TrimSpace(s[1:]))
+	if err != nil {
+		l.report(d.Line, CheckStrength, "strength %q is not valid", d.Value)
+		return
+	}
+
+	switch {
+	case op == '/' && v == 0:
+		l.report(d.Line, CheckStrength, "strength %q divides by zero", d.Value)
+	case op == '*' && v == 0:
+		l.report(d.Line, CheckStrength, "strength %q sets the strength to zero", d.Value)
+	case (op == '+' || op == '-') && v == 0, (op == '*' || op == '/') && v == 1:
+		l.report(d.Line, CheckStrength, "strength %q has no effect", d.Value)
+	case (op == '*' || op == '/') && v > 10, (op == '+' || op == '-') && v > 100:
+		l.report(d.Line, CheckStrength, "strength %q is unusually large", d.Value)
+	}
+}
+
+// Widths, in bits, of the numeric types.
+var widths = map[string]uint{
+	"byte":  8,
+	"short": 16, "beshort": 16, "leshort": 16,
+	"long": 32, "belong": 32, "lelong": 32,
+}
+
+func (l *linter) lintTest(e *parse.Entry) {
+	name := strings.TrimPrefix(e.TypeName(), "u")
+	width, ok := widths[name]
+	if !ok || strings.ContainsAny(e.Type, "&|^+-*/%") {
+		return
+	}
+
+	test := strings.TrimPrefix(e.Test, "=")
+	v, err := strconv.ParseInt(test, 0, 64)
+	if err != nil {
+		return
+	}
+	// Values are compared as either signed or unsigned, thus
+	// anything outside of both ranges will never match.
+	if v < -(1<<(width-1)) || v >= 1<<width {
+		l.report(e.Line, CheckUnreachable, "value %s does not fit into type %q, and will never match", test, e.Type)
+	}
+}
+
+// hasMIME returns true if the entry, or any of its continuation
+// entries, has a MIME type, or false otherwise.
+func hasMIME(e *parse.Entry) bool {
+	found := false
+	walk(e, func(e *parse.Entry) {
+		if e.MIME() != "" {
+			found = true
+		}
+	})
+	return found
+}
+
+func walk(e *parse.Entry, fn func(*parse.Entry)) {
+	fn(e)
+	for _, c := range e.Children {
+		walk(c, fn)
+	}
+}
+
+// sortIssues sorts the issues by the file, in the order of the names
+// given, and the line number.
+func sortIssues(issues []Issue, names []string) []Issue {
+	order := make(map[string]int)
+	for i, name := range names {
+		order[name] = i
+	}
+	sort.SliceStable(issues, func(i, j int) bool {
+		if issues[i].File != issues[j].File {
+			return order[issues[i].File] < order[issues[j].File]
+		}
+		return issues[i].Line < issues[j].Line
+	})
+	return issues
+}
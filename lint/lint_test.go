@@ -0,0 +1,114 @@
+package lint
+
+import (
+	"encoding/json"
+	"path"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/kwilczynski/go-magic/parse"
+)
+
+var fixturesDirectory = path.Join("..", "test", "fixtures")
+
+func TestFiles(t *testing.T) {
+	genuineMagicFile := path.Join(fixturesDirectory, "png.magic")
+	fakeMagicFile := path.Join(fixturesDirectory, "png-fake.magic")
+	shellMagicFile := path.Join(fixturesDirectory, "shell.magic")
+
+	issues, err := Files(genuineMagicFile, fakeMagicFile, shellMagicFile)
+	if err != nil {
+		t.Fatalf("value given {%v}, want {%v}", err, nil)
+	}
+
+	expected := []Issue{
+		{fakeMagicFile, 1, CheckDuplicate, SeverityWarning, "pattern is the same as at " + genuineMagicFile + ":1, and will never be reached"},
+		{shellMagicFile, 6, CheckMissingMIME, SeverityWarning, "entry has no MIME type"},
+		{shellMagicFile, 8, CheckStrength, SeverityWarning, "strength applies to the top-level entry, not the continuation entry"},
+	}
+	if !reflect.DeepEqual(issues, expected) {
+		t.Errorf("value given %v, want %v", issues, expected)
+	}
+
+	issues, err = Files(path.Join(fixturesDirectory, "png-broken.magic"))
+	if err != nil {
+		t.Fatalf("value given {%v}, want {%v}", err, nil)
+	}
+	if len(issues) == 0 || issues[0].Check != CheckSyntax || issues[0].Severity != SeverityError {
+		t.Errorf("value given %v, want a syntax error", issues)
+	}
+
+	if _, err := Files(path.Join(fixturesDirectory, "does-not-exist.magic")); err == nil {
+		t.Errorf("value given {%v}, want an error", err)
+	}
+}
+
+func TestLint(t *testing.T) {
+	src := strings.Join([]string{
+		`0	string	ABC	one`,
+		`!:mime	text plain`,
+		`>>4	byte	1	jump`,
+		`>4	byte	300	too large`,
+		`>4	ubyte	0xff	fits`,
+		`>4	byte&0xffff	300	masked`,
+		`>4	short	=-32769	too small`,
+		`0	string	DEF	two`,
+		`!:mime	text/x-def`,
+		`!:strength	/ 0`,
+		`0	string	GHI	three`,
+		`!:mime	text/x-ghi`,
+		`!:strength	+0`,
+		`0	string	JKL	four`,
+		`!:mime	text/x-jkl`,
+		`!:strength	* 50`,
+		`0	name	subroutine`,
+		`>0	string	MNO	five`,
+	}, "\n")
+
+	f, err := parse.Parse("test.magic", strings.NewReader(src))
+	if err != nil {
+		t.Fatalf("value given {%v}, want {%v}", err, nil)
+	}
+
+	var lintTests = []struct {
+		line  int
+		check string
+	}{
+		{2, CheckInvalidMIME},
+		{3, CheckUnreachable},
+		{4, CheckUnreachable},
+		{7, CheckUnreachable},
+		{10, CheckStrength},
+		{13, CheckStrength},
+		{16, CheckStrength},
+	}
+
+	issues := Lint(f)
+	if len(issues) != len(lintTests) {
+		t.Fatalf("value given %v, want %d issues", issues, len(lintTests))
+	}
+	for i, tt := range lintTests {
+		if issues[i].Line != tt.line || issues[i].Check != tt.check {
+			t.Errorf("value given %v, want %d (%s)", issues[i], tt.line, tt.check)
+		}
+	}
+}
+
+func TestIssue(t *testing.T) {
+	i := Issue{"png.magic", 3, CheckMissingMIME, SeverityWarning, "entry has no MIME type"}
+
+	v := "png.magic:3: warning: entry has no MIME type (missing-mime)"
+	if i.String() != v {
+		t.Errorf("value given %q, want %q", i.String(), v)
+	}
+
+	b, err := json.Marshal(i)
+	if err != nil {
+		t.Fatalf("value given {%v}, want {%v}", err, nil)
+	}
+	v = `{"file":"png.magic","line":3,"check":"missing-mime","severity":"warning","message":"entry has no MIME type"}`
+	if string(b) != v {
+		t.Errorf("value given %s, want %s", b, v)
+	}
+}
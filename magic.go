@@ -11,11 +11,11 @@ import "C"
 
 import (
 	"fmt"
-	"math"
+	"io"
 	"os"
 	"reflect"
 	"runtime"
-	"sort"
+	"strconv"
 	"strings"
 	"sync"
 	"syscall"
@@ -80,7 +80,7 @@ func WithBuffers(buffers ...[]byte) Option {
 type magic struct {
 	sync.RWMutex
 	// Current flags set (bitmask).
-	flags int
+	flags Flags
 	// List of the Magic database files currently in-use.
 	paths []string
 	// The Magic database session cookie.
@@ -91,6 +91,15 @@ type magic struct {
 	errors bool
 	// The Magic database has been loaded successfully.
 	loaded bool
+	// Copies of the buffers in C memory that the Magic database was
+	// loaded from, which the Magic library uses without copying.
+	buffers []unsafe.Pointer
+	// Writer to deliver any diagnostic output to.
+	diagnostics io.Writer
+	// File capturing the diagnostic output, if any.
+	output *os.File
+	// Serializes access to the file capturing the diagnostic output.
+	outputMutex sync.Mutex
 }
 
 // open opens and initializes the Magic library and sets the finalizer
@@ -113,10 +122,24 @@ func (m *magic) close() {
 		C.magic_close_wrapper(m.cookie)
 		m.paths = []string{}
 		m.cookie = nil
+		m.freeBuffers(nil)
+	}
+	if m != nil && m.output != nil {
+		m.output.Close()
+		m.output = nil
 	}
 	runtime.SetFinalizer(m, nil)
 }
 
+// freeBuffers frees the copies of the buffers that the Magic database
+// was loaded from, and retains the new ones in their place, if any.
+func (m *magic) freeBuffers(buffers []unsafe.Pointer) {
+	for _, p := range m.buffers {
+		C.free(p)
+	}
+	m.buffers = buffers
+}
+
 // error retrieves an error from the Magic library.
 func (m *magic) error() error {
 	if cString := C.magic_error_wrapper(m.cookie); cString != nil {
@@ -266,7 +289,7 @@ func (mgc *Magic) Parameter(parameter int) (int, error) {
 	cResult, err := C.magic_getparam_wrapper(mgc.cookie, C.int(parameter), p)
 	if cResult < 0 && err != nil {
 		if errno := err.(syscall.Errno); errno == syscall.EINVAL {
-			return -1, &Error{int(errno), "unknown or invalid parameter specified"}
+			return -1, ErrInvalidParameter
 		}
 		return -1, mgc.error()
 	}
@@ -289,7 +312,7 @@ func (mgc *Magic) SetParameter(parameter int, value int) error {
 		errno := err.(syscall.Errno)
 		switch errno {
 		case syscall.EINVAL:
-			return &Error{int(errno), "unknown or invalid parameter specified"}
+			return ErrInvalidParameter
 		case syscall.EOVERFLOW:
 			return &Error{int(errno), "invalid parameter value specified"}
 		default:
@@ -300,6 +323,9 @@ func (mgc *Magic) SetParameter(parameter int, value int) error {
 }
 
 // Flags returns a value (bitmask) representing current flags set.
+//
+// The value can be converted to the Flags type, for example, to
+// obtain names of the flags set.
 func (mgc *Magic) Flags() (int, error) {
 	mgc.RLock()
 	defer mgc.RUnlock()
@@ -311,7 +337,7 @@ func (mgc *Magic) Flags() (int, error) {
 	cRv, err := C.magic_getflags_wrapper(mgc.cookie)
 	if cRv < 0 && err != nil {
 		if err.(syscall.Errno) == syscall.ENOSYS {
-			return mgc.flags, nil
+			return int(mgc.flags), nil
 		}
 		return -1, mgc.error()
 	}
@@ -322,6 +348,9 @@ func (mgc *Magic) Flags() (int, error) {
 //
 // Depending on which flags are current set the results and/or
 // behavior of the Magic library will change accordingly.
+//
+// The value of the Flags type, such as one returned by ParseFlags,
+// can be used after converting it to an int.
 func (mgc *Magic) SetFlags(flags int) error {
 	mgc.Lock()
 	defer mgc.Unlock()
@@ -333,11 +362,11 @@ func (mgc *Magic) SetFlags(flags int) error {
 	cResult, err := C.magic_setflags_wrapper(mgc.cookie, C.int(flags))
 	if cResult < 0 && err != nil {
 		if errno := err.(syscall.Errno); errno == syscall.EINVAL {
-			return &Error{int(errno), "unknown or invalid flag specified"}
+			return ErrInvalidFlag
 		}
 		return mgc.error()
 	}
-	mgc.flags = flags
+	mgc.flags = Flags(flags)
 	return nil
 }
 
@@ -357,19 +386,12 @@ func (mgc *Magic) FlagsSlice() ([]int, error) {
 		return []int{0}, nil
 	}
 
-	var (
-		n     int
-		flags []int
-	)
-
 	// Split current value (bitmask) into a list
 	// of distinct flags (bits) currently set.
-	for i := mgc.flags; i > 0; i -= n {
-		n = int(math.Log2(float64(i)))
-		n = int(math.Pow(2, float64(n)))
-		flags = append(flags, n)
+	var flags []int
+	for _, flag := range mgc.flags.Slice() {
+		flags = append(flags, int(flag))
 	}
-	sort.Ints(flags)
 	return flags, nil
 }
 
@@ -377,7 +399,19 @@ func (mgc *Magic) FlagsSlice() ([]int, error) {
 func (mgc *Magic) Load(files ...string) error {
 	mgc.Lock()
 	defer mgc.Unlock()
+	return mgc.load(files...)
+}
+
+// LoadBuffers
+func (mgc *Magic) LoadBuffers(buffers ...[]byte) error {
+	mgc.Lock()
+	defer mgc.Unlock()
+	return mgc.loadBuffers(buffers...)
+}
 
+// load loads the Magic database files, the lock has to be
+// held by the caller.
+func (mgc *Magic) load(files ...string) error {
 	if err := verifyOpen(mgc); err != nil {
 		return err
 	}
@@ -398,54 +432,72 @@ func (mgc *Magic) Load(files ...string) error {
 		cFiles = C.magic_getpath_wrapper()
 	}
 
-	if cRv := C.magic_load_wrapper(mgc.cookie, cFiles, C.int(mgc.flags)); cRv < 0 {
+	var cRv C.int
+
+	mgc.diagnose(func(output C.int) {
+		cRv = C.magic_load_wrapper(mgc.cookie, cFiles, C.int(mgc.flags), output)
+	})
+	mgc.freeBuffers(nil)
+	if cRv < 0 {
 		mgc.loaded = false
-		return mgc.error()
+		return newOpError(mgc, "load", C.GoString(cFiles), mgc.error())
 	}
 	mgc.loaded = true
 	mgc.paths = strings.Split(C.GoString(cFiles), ":")
 	return nil
 }
 
-// LoadBuffers
-func (mgc *Magic) LoadBuffers(buffers ...[]byte) error {
-	mgc.Lock()
-	defer mgc.Unlock()
-
+// loadBuffers loads the Magic database from the buffers,
+// the lock has to be held by the caller.
+func (mgc *Magic) loadBuffers(buffers ...[]byte) error {
 	if err := verifyOpen(mgc); err != nil {
 		return err
 	}
 
 	var (
-		empty []byte
-		p     *unsafe.Pointer
-		s     *C.size_t
+		p *unsafe.Pointer
+		s *C.size_t
 	)
 	// Clear paths. To be set again when the Magic
 	// database files are successfully loaded.
 	mgc.paths = []string{}
 
 	cSize := C.size_t(len(buffers))
-	cPointers := make([]uintptr, cSize)
+	cPointers := make([]unsafe.Pointer, cSize)
 	cSizes := make([]C.size_t, cSize)
 
+	// The Magic library does not copy the buffers, and uses them for
+	// as long as the Magic database is loaded, thus these are copied
+	// into C memory, which is freed only once no longer in use.
 	for i := range buffers {
 		// An attempt to load the Magic database from a number of
 		// buffers in memory where a single buffer is empty would
 		// result in a failure.
-		cPointers[i] = uintptr(unsafe.Pointer(&empty))
 		if s := len(buffers[i]); s > 0 {
-			cPointers[i] = uintptr(unsafe.Pointer(&buffers[i][0]))
+			cPointers[i] = C.CBytes(buffers[i])
 			cSizes[i] = C.size_t(s)
+		} else {
+			cPointers[i] = C.malloc(1)
 		}
 	}
 
 	if cSize > 0 {
-		p = (*unsafe.Pointer)(unsafe.Pointer(&cPointers[0]))
-		s = (*C.size_t)(unsafe.Pointer(&cSizes[0]))
+		p = &cPointers[0]
+		s = &cSizes[0]
 	}
 
-	if cRv := C.magic_load_buffers_wrapper(mgc.cookie, p, s, cSize, C.int(mgc.flags)); cRv < 0 {
+	var cRv C.int
+
+	mgc.diagnose(func(output C.int) {
+		cRv = C.magic_load_buffers_wrapper(mgc.cookie, p, s, cSize, C.int(mgc.flags), output)
+	})
+	// The previously loaded Magic database is no longer in use
+	// regardless of whether the new one could be loaded or not.
+	if cRv < 0 {
+		mgc.freeBuffers(nil)
+		for _, p := range cPointers {
+			C.free(p)
+		}
 		mgc.loaded = false
 		// Loading a compiled Magic database from a buffer in memory can
 		// often cause failure, sadly there isn't a proper error messages
@@ -456,6 +508,7 @@ func (mgc *Magic) LoadBuffers(buffers ...[]byte) error {
 		}
 		return &Error{-1, "unable to load Magic database"}
 	}
+	mgc.freeBuffers(cPointers)
 	mgc.loaded = true
 	return nil
 }
@@ -472,8 +525,13 @@ func (mgc *Magic) Compile(file string) error {
 	cFile := C.CString(file)
 	defer C.free(unsafe.Pointer(cFile))
 
-	if cRv := C.magic_compile_wrapper(mgc.cookie, cFile, C.int(mgc.flags)); cRv < 0 {
-		return mgc.error()
+	var cRv C.int
+
+	mgc.diagnose(func(output C.int) {
+		cRv = C.magic_compile_wrapper(mgc.cookie, cFile, C.int(mgc.flags), output)
+	})
+	if cRv < 0 {
+		return newOpError(mgc, "compile", file, mgc.error())
 	}
 	return nil
 }
@@ -490,8 +548,13 @@ func (mgc *Magic) Check(file string) (bool, error) {
 	cFile := C.CString(file)
 	defer C.free(unsafe.Pointer(cFile))
 
-	if cRv := C.magic_check_wrapper(mgc.cookie, cFile, C.int(mgc.flags)); cRv < 0 {
-		return false, mgc.error()
+	var cRv C.int
+
+	mgc.diagnose(func(output C.int) {
+		cRv = C.magic_check_wrapper(mgc.cookie, cFile, C.int(mgc.flags), output)
+	})
+	if cRv < 0 {
+		return false, newOpError(mgc, "check", file, mgc.error())
 	}
 	return true, nil
 }
@@ -500,7 +563,26 @@ func (mgc *Magic) Check(file string) (bool, error) {
 func (mgc *Magic) File(file string) (string, error) {
 	mgc.RLock()
 	defer mgc.RUnlock()
+	return mgc.file(file)
+}
 
+// Buffer
+func (mgc *Magic) Buffer(buffer []byte) (string, error) {
+	mgc.RLock()
+	defer mgc.RUnlock()
+	return mgc.buffer(buffer)
+}
+
+// Descriptor
+func (mgc *Magic) Descriptor(fd uintptr) (string, error) {
+	mgc.RLock()
+	defer mgc.RUnlock()
+	return mgc.descriptor(fd)
+}
+
+// file returns a textual description of the named file, the
+// lock has to be held by the caller.
+func (mgc *Magic) file(file string) (string, error) {
 	if err := verifyOpen(mgc); err != nil {
 		return "", err
 	}
@@ -514,7 +596,9 @@ func (mgc *Magic) File(file string) (string, error) {
 	var cString *C.char
 
 	flagsSaveAndRestore(mgc, func() {
-		cString = C.magic_file_wrapper(mgc.cookie, cFile, C.int(mgc.flags))
+		mgc.diagnose(func(output C.int) {
+			cString = C.magic_file_wrapper(mgc.cookie, cFile, C.int(mgc.flags), output)
+		})
 	})
 	if cString == nil {
 		// Handle the case when the "ERROR" flag is set regardless
@@ -529,18 +613,20 @@ func (mgc *Magic) File(file string) (string, error) {
 		// This is an attempt to mitigate the problem and correct
 		// it to achieve the desired behavior as per the standards.
 		if mgc.errors || mgc.flags&ERROR != 0 {
-			return "", mgc.error()
+			return "", newOpError(mgc, "file", file, mgc.error())
 		}
 		cString = C.magic_error_wrapper(mgc.cookie)
 	}
-	return errorOrString(mgc, cString)
+	s, err := errorOrString(mgc, cString)
+	if err != nil {
+		return "", newOpError(mgc, "file", file, err)
+	}
+	return s, nil
 }
 
-// Buffer
-func (mgc *Magic) Buffer(buffer []byte) (string, error) {
-	mgc.RLock()
-	defer mgc.RUnlock()
-
+// buffer returns a textual description of the content of the
+// buffer, the lock has to be held by the caller.
+func (mgc *Magic) buffer(buffer []byte) (string, error) {
 	if err := verifyOpen(mgc); err != nil {
 		return "", err
 	}
@@ -559,16 +645,16 @@ func (mgc *Magic) Buffer(buffer []byte) (string, error) {
 	}
 
 	flagsSaveAndRestore(mgc, func() {
-		cString = C.magic_buffer_wrapper(mgc.cookie, p, cSize, C.int(mgc.flags))
+		mgc.diagnose(func(output C.int) {
+			cString = C.magic_buffer_wrapper(mgc.cookie, p, cSize, C.int(mgc.flags), output)
+		})
 	})
 	return errorOrString(mgc, cString)
 }
 
-// Descriptor
-func (mgc *Magic) Descriptor(fd uintptr) (string, error) {
-	mgc.RLock()
-	defer mgc.RUnlock()
-
+// descriptor returns a textual description of the content of
+// the file descriptor, the lock has to be held by the caller.
+func (mgc *Magic) descriptor(fd uintptr) (string, error) {
 	if err := verifyOpen(mgc); err != nil {
 		return "", err
 	}
@@ -582,14 +668,20 @@ func (mgc *Magic) Descriptor(fd uintptr) (string, error) {
 	)
 
 	flagsSaveAndRestore(mgc, func() {
-		cString, err = C.magic_descriptor_wrapper(mgc.cookie, C.int(fd), C.int(mgc.flags))
+		mgc.diagnose(func(output C.int) {
+			cString, err = C.magic_descriptor_wrapper(mgc.cookie, C.int(fd), C.int(mgc.flags), output)
+		})
 	})
 	if err != nil {
 		if errno := err.(syscall.Errno); errno == syscall.EBADF {
-			return "", &Error{int(errno), "bad file descriptor"}
+			return "", newOpError(mgc, "descriptor", descriptorPath(fd), ErrBadDescriptor)
 		}
 	}
-	return errorOrString(mgc, cString)
+	s, err := errorOrString(mgc, cString)
+	if err != nil {
+		return "", newOpError(mgc, "descriptor", descriptorPath(fd), err)
+	}
+	return s, nil
 }
 
 // Open
@@ -756,7 +848,7 @@ func verifyOpen(mgc *Magic) error {
 	if mgc != nil && mgc.cookie != nil {
 		return nil
 	}
-	return &Error{int(syscall.EFAULT), "Magic library is not open"}
+	return ErrNotOpen
 }
 
 func verifyLoaded(mgc *Magic) error {
@@ -765,11 +857,11 @@ func verifyLoaded(mgc *Magic) error {
 	if err := verifyOpen(mgc); err == nil && mgc.loaded {
 		return nil
 	}
-	return &Error{-1, "Magic database not loaded"}
+	return ErrNotLoaded
 }
 
 func flagsSaveAndRestore(mgc *Magic, f func()) {
-	var flags int
+	var flags Flags
 
 	flags, mgc.flags = mgc.flags, mgc.flags|RAW
 	// Make sure to set the "ERROR" flag so that any
@@ -788,10 +880,25 @@ func flagsSaveAndRestore(mgc *Magic, f func()) {
 			C.magic_setflags_wrapper(mgc.cookie, C.int(mgc.flags))
 		}
 	}()
-	mgc.flags = flags
+	mgc.flags = Flags(flags)
 	f()
 }
 
+// newOpError returns an OpError for the operation on the file with the
+// flags currently set, or nil if there is no error.
+func newOpError(mgc *Magic, op, path string, err error) error {
+	if err == nil {
+		return nil
+	}
+	return &OpError{Op: op, Path: path, Flags: mgc.flags, Err: err}
+}
+
+// descriptorPath returns the name of the file descriptor as used
+// by the OpError type e.g., "fd 3".
+func descriptorPath(fd uintptr) string {
+	return "fd " + strconv.FormatUint(uint64(fd), 10)
+}
+
 func errorOrString(mgc *Magic, cString *C.char) (string, error) {
 	if cString == nil {
 		return "", &Error{-1, "unknown result or nil pointer"}
@@ -815,7 +922,7 @@ func errorOrString(mgc *Magic, cString *C.char) (string, error) {
 		// yield no results or return the "(null)" string
 		// instead. Often this would indicate that an
 		// older version of the Magic library is in use.
-		return "", &Error{-1, "empty or invalid result"}
+		return "", ErrEmptyResult
 	}
 	return "", mgc.error()
 }
@@ -1,5 +1,12 @@
 package magic
 
+import (
+	"io/ioutil"
+	"sync"
+	"syscall"
+	"testing"
+)
+
 // func TestNew(t *testing.T) {
 // 	var mgc *Magic
 
@@ -1897,3 +1904,114 @@ package magic
 // 	// Will panic ...
 // 	BufferEncoding(buffer.Bytes())
 // }
+
+func TestMagic_ErrorOutput(t *testing.T) {
+	var before, after syscall.Stat_t
+
+	if err := syscall.Fstat(2, &before); err != nil {
+		t.Fatalf("unable to stat standard error output: %s", err.Error())
+	}
+
+	var wg sync.WaitGroup
+
+	// Redirection of the standard error output happening
+	// concurrently should not leave it redirected.
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			mgc, err := New(DisableAutoload, WithFlags(CHECK))
+			if err != nil {
+				t.Errorf("unable to create new Magic type: %s", err.Error())
+				return
+			}
+			defer mgc.Close()
+
+			for j := 0; j < 16; j++ {
+				mgc.Check(shellMagicFile)
+				mgc.Load(shellMagicFile)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if err := syscall.Fstat(2, &after); err != nil {
+		t.Fatalf("unable to stat standard error output: %s", err.Error())
+	}
+	if before.Dev != after.Dev || before.Ino != after.Ino {
+		t.Errorf("value given %d:%d, want %d:%d", after.Dev, after.Ino, before.Dev, before.Ino)
+	}
+}
+
+func benchmarkMagic(b *testing.B, flags int, f func(*Magic) error) {
+	mgc, err := New(WithFlags(flags))
+	if err != nil {
+		b.Fatalf("unable to create new Magic type: %s", err.Error())
+	}
+	defer mgc.Close()
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		if err := f(mgc); err != nil {
+			b.Fatalf("value given {%v}, want {%v}", err, nil)
+		}
+	}
+}
+
+func BenchmarkMagic_File(b *testing.B) {
+	benchmarkMagic(b, MIME_TYPE, func(mgc *Magic) error {
+		_, err := mgc.File(sampleImageFile)
+		return err
+	})
+}
+
+func BenchmarkMagic_Buffer(b *testing.B) {
+	buffer, err := ioutil.ReadFile(sampleImageFile)
+	if err != nil {
+		b.Fatalf("unable to read file %q: %s", sampleImageFile, err.Error())
+	}
+	benchmarkMagic(b, MIME_TYPE, func(mgc *Magic) error {
+		_, err := mgc.Buffer(buffer[:1024])
+		return err
+	})
+}
+
+func BenchmarkMagic_Buffer_Check(b *testing.B) {
+	buffer, err := ioutil.ReadFile(sampleImageFile)
+	if err != nil {
+		b.Fatalf("unable to read file %q: %s", sampleImageFile, err.Error())
+	}
+	// The CHECK flag requires the output to be redirected.
+	benchmarkMagic(b, MIME_TYPE|CHECK, func(mgc *Magic) error {
+		_, err := mgc.Buffer(buffer[:1024])
+		return err
+	})
+}
+
+func BenchmarkPool_Buffer(b *testing.B) {
+	buffer, err := ioutil.ReadFile(sampleImageFile)
+	if err != nil {
+		b.Fatalf("unable to read file %q: %s", sampleImageFile, err.Error())
+	}
+
+	p, err := NewPool(8, WithFlags(MIME_TYPE))
+	if err != nil {
+		b.Fatalf("unable to create new Pool type: %s", err.Error())
+	}
+	defer p.Close()
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			if _, err := p.Buffer(buffer[:1024]); err != nil {
+				b.Errorf("value given {%v}, want {%v}", err, nil)
+				return
+			}
+		}
+	})
+}
@@ -0,0 +1,98 @@
+package parse
+
+// File represents a source Magic file.
+type File struct {
+	Name     string     // The name of the file, if any.
+	Entries  []*Entry   // List of the top-level entries.
+	Comments []*Comment // List of all the comments, in order.
+}
+
+// Walk calls the function for each entry, including the continuation
+// entries, in the order these appear in the source Magic file.
+func (f *File) Walk(fn func(*Entry)) {
+	walk(f.Entries, fn)
+}
+
+func walk(entries []*Entry, fn func(*Entry)) {
+	for _, e := range entries {
+		fn(e)
+		walk(e.Children, fn)
+	}
+}
+
+// Entry represents a single entry (a line) of a source Magic file,
+// e.g., "0 string \x89PNG\x0d\x0a\x1a\x0a PNG image data", together
+// with its directives and continuation entries.
+type Entry struct {
+	Line       int          // The line number, starting at 1.
+	Level      int          // The continuation level, that is number of ">".
+	Offset     string       // The offset, e.g., "16", "&0" or "(4.l+4)".
+	Type       string       // The type, e.g., "belong" or "string/wt".
+	Test       string       // The test, e.g., "x" or "#!\ /bin/sh".
+	Message    string       // The message, if any.
+	Directives []*Directive // List of the directives, e.g., "!:mime".
+	Children   []*Entry     // List of the continuation entries.
+}
+
+// TypeName returns the name of the type without any of the flags or
+// the mask, e.g., "string" for "string/wt" or "belong" for "belong&0xff".
+func (e *Entry) TypeName() string {
+	for i, r := range e.Type {
+		if !(r >= 'a' && r <= 'z' || r >= '0' && r <= '9') {
+			return e.Type[:i]
+		}
+	}
+	return e.Type
+}
+
+// Directive returns the value of the first directive of the given
+// name, e.g., "mime", and true, or false if there isn't one.
+func (e *Entry) Directive(name string) (string, bool) {
+	for _, d := range e.Directives {
+		if d.Name == name {
+			return d.Value, true
+		}
+	}
+	return "", false
+}
+
+// MIME returns the value of the "!:mime" directive, if any.
+func (e *Entry) MIME() string {
+	s, _ := e.Directive("mime")
+	return s
+}
+
+// Strength returns the value of the "!:strength" directive, if any.
+//
+// Note that the Magic library applies the strength to the top-level
+// entry, even when the directive follows a continuation entry.
+func (e *Entry) Strength() string {
+	s, _ := e.Directive("strength")
+	return s
+}
+
+// Ext returns the value of the "!:ext" directive, if any.
+func (e *Entry) Ext() string {
+	s, _ := e.Directive("ext")
+	return s
+}
+
+// Apple returns the value of the "!:apple" directive, if any.
+func (e *Entry) Apple() string {
+	s, _ := e.Directive("apple")
+	return s
+}
+
+// Directive represents a directive that applies to the entry that
+// precedes it, e.g., "!:mime image/png".
+type Directive struct {
+	Line  int    // The line number, starting at 1.
+	Name  string // The name, e.g., "mime".
+	Value string // The value, e.g., "image/png".
+}
+
+// Comment represents a comment, e.g., "# PNG images".
+type Comment struct {
+	Line int    // The line number, starting at 1.
+	Text string // The text, including the leading "#".
+}
@@ -0,0 +1,12 @@
+/*
+Package parse implements a parser for the source Magic files, as described
+in the magic(5) manual page, that yields a syntax tree of the entries, along
+with their continuation entries, directives and comments, and the position
+of each within the source.
+
+The parser does not interpret the content of the offset, type, test and
+message fields beyond splitting these apart, and keeps these exactly as
+written, so that the syntax tree can be used by tools such as linters and
+formatters without loss of information.
+*/
+package parse
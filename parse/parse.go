@@ -0,0 +1,190 @@
+package parse
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// Directives known to the Magic library.
+var directives = map[string]bool{
+	"apple":    true,
+	"ext":      true,
+	"mime":     true,
+	"strength": true,
+}
+
+// Error represents an error found in the source Magic file.
+type Error struct {
+	Name string // The name of the file, if any.
+	Line int    // The line number, starting at 1.
+	Msg  string // The actual error message.
+}
+
+// Error returns a descriptive error message in the "file:line: message"
+// format.
+func (e *Error) Error() string {
+	if e.Name != "" {
+		return fmt.Sprintf("%s:%d: %s", e.Name, e.Line, e.Msg)
+	}
+	return fmt.Sprintf("line %d: %s", e.Line, e.Msg)
+}
+
+// ErrorList represents a list of errors found in the source Magic file.
+type ErrorList []*Error
+
+// Error returns a descriptive error message of the first error, and
+// the number of other errors, if any.
+func (l ErrorList) Error() string {
+	switch len(l) {
+	case 0:
+		return "no errors"
+	case 1:
+		return l[0].Error()
+	}
+	return fmt.Sprintf("%s (and %d more errors)", l[0], len(l)-1)
+}
+
+// ParseFile parses the named source Magic file, as per Parse.
+func ParseFile(name string) (*File, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return Parse(name, f)
+}
+
+// Parse parses the source Magic file read from the reader, and returns
+// its syntax tree. The name is used in the error messages only.
+//
+// Lines that are not valid are skipped, and the errors are returned as
+// the ErrorList type, alongside the syntax tree of the remaining lines.
+func Parse(name string, r io.Reader) (*File, error) {
+	p := &parser{file: &File{Name: name}}
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(nil, 1<<20)
+	for scanner.Scan() {
+		p.line++
+		p.parseLine(strings.TrimRight(scanner.Text(), "\r"))
+	}
+	if err := scanner.Err(); err != nil {
+		return p.file, err
+	}
+	if len(p.errors) > 0 {
+		return p.file, p.errors
+	}
+	return p.file, nil
+}
+
+type parser struct {
+	file   *File
+	line   int
+	errors ErrorList
+	// The entries that the next entry can be a continuation of,
+	// starting with the top-level entry.
+	stack []*Entry
+	// The last entry, to which the directives apply.
+	last *Entry
+}
+
+func (p *parser) error(format string, args ...interface{}) {
+	p.errors = append(p.errors, &Error{p.file.Name, p.line, fmt.Sprintf(format, args...)})
+}
+
+func (p *parser) parseLine(s string) {
+	switch {
+	case strings.TrimSpace(s) == "":
+		return
+	case s[0] == '#':
+		p.file.Comments = append(p.file.Comments, &Comment{p.line, strings.TrimRight(s, " \t")})
+	case strings.HasPrefix(s, "!:"):
+		p.parseDirective(s[2:])
+	default:
+		p.parseEntry(s)
+	}
+}
+
+func (p *parser) parseDirective(s string) {
+	name, value := nextField(s)
+	if !directives[name] {
+		p.error("unknown directive %q", "!:"+name)
+		return
+	}
+	if p.last == nil {
+		p.error("directive %q without a preceding entry", "!:"+name)
+		return
+	}
+	p.last.Directives = append(p.last.Directives, &Directive{
+		Line:  p.line,
+		Name:  name,
+		Value: strings.TrimSpace(value),
+	})
+}
+
+func (p *parser) parseEntry(s string) {
+	e := &Entry{Line: p.line}
+
+	s = strings.TrimLeft(s, " \t")
+	for strings.HasPrefix(s, ">") {
+		e.Level++
+		s = strings.TrimLeft(s[1:], " \t")
+	}
+
+	e.Offset, s = nextField(s)
+	e.Type, s = nextField(s)
+	e.Test, s = nextField(s)
+	e.Message = strings.TrimLeft(s, " \t")
+
+	switch {
+	case e.Offset == "":
+		p.error("missing offset")
+		return
+	case e.Type == "":
+		p.error("missing type")
+		return
+	case e.Test == "":
+		p.error("missing test")
+		return
+	}
+
+	if e.Level == 0 {
+		p.file.Entries = append(p.file.Entries, e)
+		p.stack = append(p.stack[:0], e)
+		p.last = e
+		return
+	}
+	if len(p.stack) == 0 {
+		p.error("continuation entry without a preceding top-level entry")
+		return
+	}
+
+	// Should the continuation level be more than one level deeper
+	// than the preceding entry, the Magic library warns about it,
+	// but still uses the entry, which is kept as is here.
+	for p.stack[len(p.stack)-1].Level >= e.Level {
+		p.stack = p.stack[:len(p.stack)-1]
+	}
+	parent := p.stack[len(p.stack)-1]
+	parent.Children = append(parent.Children, e)
+	p.stack = append(p.stack, e)
+	p.last = e
+}
+
+// nextField returns the next field that is separated by a whitespace,
+// taking into account any escaped whitespace, and the remaining string.
+func nextField(s string) (string, string) {
+	s = strings.TrimLeft(s, " \t")
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			i++
+		case ' ', '\t':
+			return s[:i], s[i:]
+		}
+	}
+	return s, ""
+}
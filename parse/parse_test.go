@@ -0,0 +1,172 @@
+package parse
+
+import (
+	"path"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+var fixturesDirectory = path.Join("..", "test", "fixtures")
+
+func TestParseFile(t *testing.T) {
+	f, err := ParseFile(path.Join(fixturesDirectory, "png.magic"))
+	if err != nil {
+		t.Fatalf("value given {%v}, want {%v}", err, nil)
+	}
+
+	if n := len(f.Entries); n != 1 {
+		t.Fatalf("value given %d, want %d", n, 1)
+	}
+
+	e := f.Entries[0]
+	expected := &Entry{
+		Line:    1,
+		Offset:  "0",
+		Type:    "string",
+		Test:    `\x89PNG\x0d\x0a\x1a\x0a`,
+		Message: "PNG image data",
+	}
+	if e.Line != expected.Line || e.Level != expected.Level || e.Offset != expected.Offset ||
+		e.Type != expected.Type || e.Test != expected.Test || e.Message != expected.Message {
+		t.Errorf("value given %+v, want %+v", e, expected)
+	}
+	if v := "image/png"; e.MIME() != v {
+		t.Errorf("value given %q, want %q", e.MIME(), v)
+	}
+	if d := e.Directives[0]; d.Line != 2 {
+		t.Errorf("value given %d, want %d", d.Line, 2)
+	}
+
+	if n := len(e.Children); n != 10 {
+		t.Fatalf("value given %d, want %d", n, 10)
+	}
+	c := e.Children[0]
+	expected = &Entry{Line: 3, Level: 1, Offset: "16", Type: "belong", Test: "x", Message: `\b, %d x`}
+	if !reflect.DeepEqual(c, expected) {
+		t.Errorf("value given %+v, want %+v", c, expected)
+	}
+
+	_, err = ParseFile(path.Join(fixturesDirectory, "does-not-exist.magic"))
+	if err == nil {
+		t.Errorf("value given {%v}, want an error", err)
+	}
+}
+
+func TestParse(t *testing.T) {
+	f, err := ParseFile(path.Join(fixturesDirectory, "shell.magic"))
+	if err != nil {
+		t.Fatalf("value given {%v}, want {%v}", err, nil)
+	}
+
+	var parseTests = []struct {
+		line                           int
+		offset, kind, name, test, text string
+		mime, strength                 string
+	}{
+		{1, "0", "string/wt", "string", `#!\ /bin/sh`, "POSIX shell script text executable", "text/x-shellscript", ""},
+		{3, "0", "string/wt", "string", `#!\ /bin/bash`, "Bourne-Again shell script text executable", "text/x-shellscript", ""},
+		{6, "0", "string/t", "string", "#!/", "a", "", ""},
+		{7, "2", "string", "string", `>\0`, "%s script text executable", "", "/ 2"},
+	}
+
+	var entries []*Entry
+	f.Walk(func(e *Entry) {
+		entries = append(entries, e)
+	})
+	if len(entries) != len(parseTests) {
+		t.Fatalf("value given %d, want %d", len(entries), len(parseTests))
+	}
+
+	for i, tt := range parseTests {
+		e := entries[i]
+		if e.Line != tt.line || e.Offset != tt.offset || e.Type != tt.kind || e.Test != tt.test || e.Message != tt.text {
+			t.Errorf("value given %+v, want %+v", e, tt)
+		}
+		if e.TypeName() != tt.name {
+			t.Errorf("value given %q, want %q", e.TypeName(), tt.name)
+		}
+		if e.MIME() != tt.mime || e.Strength() != tt.strength {
+			t.Errorf("value given %q %q, want %q %q", e.MIME(), e.Strength(), tt.mime, tt.strength)
+		}
+	}
+}
+
+func TestParse_Levels(t *testing.T) {
+	src := `# Comment
+0	byte	1	one
+>1	byte	2	two
+>>2	byte	3	three
+>>>>3	byte	4	four
+>>>>3	byte	5	five
+>1	byte	6	six
+
+0	belong&0xff	=7	seven	 with spaces
+>(4.l+4)	ubyte	!8
+`
+	f, err := Parse("test.magic", strings.NewReader(src))
+	if err != nil {
+		t.Fatalf("value given {%v}, want {%v}", err, nil)
+	}
+
+	expected := []*Comment{{1, "# Comment"}}
+	if !reflect.DeepEqual(f.Comments, expected) {
+		t.Errorf("value given %+v, want %+v", f.Comments, expected)
+	}
+
+	if n := len(f.Entries); n != 2 {
+		t.Fatalf("value given %d, want %d", n, 2)
+	}
+
+	one := f.Entries[0]
+	if n := len(one.Children); n != 2 {
+		t.Fatalf("value given %d, want %d", n, 2)
+	}
+	three := one.Children[0].Children[0]
+	if three.Message != "three" || len(three.Children) != 2 {
+		t.Errorf("value given %+v, want %q with %d children", three, "three", 2)
+	}
+	if four := three.Children[0]; four.Level != 4 || four.Message != "four" {
+		t.Errorf("value given %+v, want %q at level %d", four, "four", 4)
+	}
+
+	seven := f.Entries[1]
+	if seven.TypeName() != "belong" || seven.Test != "=7" || seven.Message != "seven\t with spaces" {
+		t.Errorf("value given %+v", seven)
+	}
+	if c := seven.Children[0]; c.Offset != "(4.l+4)" || c.Test != "!8" || c.Message != "" {
+		t.Errorf("value given %+v", c)
+	}
+}
+
+func TestParse_Errors(t *testing.T) {
+	src := `!:mime	text/plain
+>0	byte	1	orphan
+0	byte
+0	byte	1	one
+!:unknown	value
+!:ext	txt
+`
+	f, err := Parse("test.magic", strings.NewReader(src))
+
+	errors, ok := err.(ErrorList)
+	if !ok {
+		t.Fatalf("value given {%v}, want an ErrorList type", err)
+	}
+
+	var v []int
+	for _, e := range errors {
+		v = append(v, e.Line)
+	}
+	if expected := []int{1, 2, 3, 5}; !reflect.DeepEqual(v, expected) {
+		t.Errorf("value given %v, want %v", v, expected)
+	}
+
+	if v := "test.magic:1: directive \"!:mime\" without a preceding entry (and 3 more errors)"; err.Error() != v {
+		t.Errorf("value given %q, want %q", err.Error(), v)
+	}
+
+	if len(f.Entries) != 1 || f.Entries[0].Ext() != "txt" {
+		t.Errorf("value given %+v, want a single entry", f.Entries)
+	}
+}
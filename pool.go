@@ -0,0 +1,233 @@
+package magic
+
+import (
+	"context"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// PoolStats represents statistics of the Pool.
+type PoolStats struct {
+	MaxOpen int // Maximum number of the Magic objects in the pool.
+	Open    int // Number of the Magic objects currently open.
+	InUse   int // Number of the Magic objects currently in use.
+	Idle    int // Number of the Magic objects currently idle.
+
+	WaitCount    int64         // Total number of times a call had to wait.
+	WaitDuration time.Duration // Total time spent waiting for the Magic object.
+}
+
+// Pool represents a pool of the Magic objects, each with its own
+// session cookie, which can be used safely from multiple goroutines.
+//
+// The Magic library session cookies are not safe to be used
+// concurrently, thus each call is handed an idle Magic object for
+// its sole use, and a new one is created on demand as long as the
+// maximum size of the pool permits it, otherwise the call waits
+// until one becomes available.
+type Pool struct {
+	sync.Mutex
+	// Options used to create each new Magic object.
+	options []Option
+	// Maximum number of the Magic objects in the pool.
+	size int
+	// The Magic objects that are currently idle.
+	idle chan *Magic
+	// Closed when the pool is closed to release waiting calls.
+	done chan struct{}
+	// Number of the Magic objects currently open.
+	open int
+	// Number of the Magic objects currently in use.
+	inUse int
+	// Statistics about calls that had to wait.
+	waitCount    int64
+	waitDuration time.Duration
+	// The pool has been closed.
+	closed bool
+}
+
+// NewPool creates a new pool holding up to size of the Magic objects,
+// each created with the same options, which are the same as the ones
+// that New accepts.
+//
+// The Magic objects are created lazily, as needed, except for the
+// first one, which is created immediately so that any issues with
+// the options given, such as the Magic database files that cannot
+// be loaded, are reported straight away.
+//
+// Remember to call Close to release initialized resources.
+func NewPool(size int, options ...Option) (*Pool, error) {
+	if size < 1 {
+		return nil, &Error{int(syscall.EINVAL), "invalid pool size specified"}
+	}
+
+	mgc, err := New(options...)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &Pool{
+		options: options,
+		size:    size,
+		idle:    make(chan *Magic, size),
+		done:    make(chan struct{}),
+		open:    1,
+	}
+	p.idle <- mgc
+	return p, nil
+}
+
+// Close closes all the Magic objects in the pool. Any calls waiting
+// for the Magic object will fail, and any of the Magic objects that
+// are currently in use will be closed once the call using it returns.
+func (p *Pool) Close() {
+	p.Lock()
+	defer p.Unlock()
+
+	if p.closed {
+		return
+	}
+	p.closed = true
+	close(p.done)
+
+	for {
+		select {
+		case mgc := <-p.idle:
+			mgc.Close()
+			p.open--
+		default:
+			return
+		}
+	}
+}
+
+// Stats returns statistics of the pool.
+func (p *Pool) Stats() PoolStats {
+	p.Lock()
+	defer p.Unlock()
+	return PoolStats{
+		MaxOpen:      p.size,
+		Open:         p.open,
+		InUse:        p.inUse,
+		Idle:         len(p.idle),
+		WaitCount:    p.waitCount,
+		WaitDuration: p.waitDuration,
+	}
+}
+
+// File returns a textual description of the named file,
+// as per the File function of the Magic type.
+func (p *Pool) File(file string) (s string, err error) {
+	err = p.do(func(mgc *Magic) (err error) {
+		s, err = mgc.File(file)
+		return
+	})
+	return
+}
+
+// Buffer returns a textual description of the content of
+// the buffer, as per the Buffer function of the Magic type.
+func (p *Pool) Buffer(buffer []byte) (s string, err error) {
+	err = p.do(func(mgc *Magic) (err error) {
+		s, err = mgc.Buffer(buffer)
+		return
+	})
+	return
+}
+
+// Descriptor returns a textual description of the content
+// of the file descriptor, as per the Descriptor function
+// of the Magic type.
+func (p *Pool) Descriptor(fd uintptr) (s string, err error) {
+	err = p.do(func(mgc *Magic) (err error) {
+		s, err = mgc.Descriptor(fd)
+		return
+	})
+	return
+}
+
+// do calls the function with the Magic object for its sole use.
+func (p *Pool) do(f func(*Magic) error) error {
+	mgc, err := p.get(context.Background())
+	if err != nil {
+		return err
+	}
+	defer p.put(mgc)
+	return f(mgc)
+}
+
+// get returns an idle Magic object, creating a new one if the size
+// of the pool permits it, otherwise waits for one to become idle
+// or until the context is done.
+func (p *Pool) get(ctx context.Context) (*Magic, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	p.Lock()
+	if p.closed {
+		p.Unlock()
+		return nil, ErrNotOpen
+	}
+
+	select {
+	case mgc := <-p.idle:
+		p.inUse++
+		p.Unlock()
+		return mgc, nil
+	default:
+	}
+
+	if p.open < p.size {
+		p.open++
+		p.inUse++
+		p.Unlock()
+
+		mgc, err := New(p.options...)
+		if err != nil {
+			p.Lock()
+			p.open--
+			p.inUse--
+			p.Unlock()
+			return nil, err
+		}
+		return mgc, nil
+	}
+	p.Unlock()
+
+	start := time.Now()
+	defer func() {
+		p.Lock()
+		p.waitCount++
+		p.waitDuration += time.Since(start)
+		p.Unlock()
+	}()
+
+	select {
+	case mgc := <-p.idle:
+		p.Lock()
+		p.inUse++
+		p.Unlock()
+		return mgc, nil
+	case <-p.done:
+		return nil, ErrNotOpen
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// put returns the Magic object back to the pool, or closes
+// it should the pool be closed in the meantime.
+func (p *Pool) put(mgc *Magic) {
+	p.Lock()
+	defer p.Unlock()
+
+	p.inUse--
+	if p.closed || mgc.IsClosed() {
+		mgc.Close()
+		p.open--
+		return
+	}
+	p.idle <- mgc
+}
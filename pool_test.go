@@ -0,0 +1,87 @@
+package magic
+
+import (
+	"sync"
+	"testing"
+)
+
+func TestNewPool(t *testing.T) {
+	_, err := NewPool(0)
+
+	v := "magic: invalid pool size specified"
+	if err == nil || !compareStrings(err.Error(), v) {
+		t.Errorf("value given {%v}, want {%q}", err, v)
+	}
+
+	p, err := NewPool(4)
+	if err != nil {
+		t.Fatalf("unable to create new Pool type: %s", err.Error())
+	}
+	defer p.Close()
+
+	// Only a single Magic object should be created upfront.
+	stats := p.Stats()
+	if stats.MaxOpen != 4 || stats.Open != 1 || stats.Idle != 1 || stats.InUse != 0 {
+		t.Errorf("value given %+v, want open 1 and idle 1 out of 4", stats)
+	}
+}
+
+func TestPool_File(t *testing.T) {
+	p, err := NewPool(2, WithFlags(MIME_TYPE))
+	if err != nil {
+		t.Fatalf("unable to create new Pool type: %s", err.Error())
+	}
+	defer p.Close()
+
+	var wg sync.WaitGroup
+
+	errors := make(chan error, 16)
+	for i := 0; i < cap(errors); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			rv, err := p.File(sampleImageFile)
+			if err != nil {
+				errors <- err
+				return
+			}
+			if v := "image/png"; !compareStrings(rv, v) {
+				t.Errorf("value given %q, want %q", rv, v)
+			}
+		}()
+	}
+	wg.Wait()
+	close(errors)
+
+	for err := range errors {
+		t.Errorf("value given {%v}, want {%v}", err, nil)
+	}
+
+	stats := p.Stats()
+	if stats.Open > 2 || stats.InUse != 0 || stats.Idle != stats.Open {
+		t.Errorf("value given %+v, want at most 2 open and all idle", stats)
+	}
+}
+
+func TestPool_Close(t *testing.T) {
+	p, err := NewPool(2)
+	if err != nil {
+		t.Fatalf("unable to create new Pool type: %s", err.Error())
+	}
+	p.Close()
+
+	if stats := p.Stats(); stats.Open != 0 || stats.Idle != 0 {
+		t.Errorf("value given %+v, want none open", stats)
+	}
+
+	_, err = p.Buffer([]byte("Hello, World!"))
+
+	v := "magic: Magic library is not open"
+	if err == nil || !compareStrings(err.Error(), v) {
+		t.Errorf("value given {%v}, want {%q}", err, v)
+	}
+
+	// Should be a no-op ...
+	p.Close()
+}
@@ -0,0 +1,97 @@
+package magic
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+)
+
+// Reader returns a textual description of the data read from the
+// reader (or MIME identification, etc., depending on the flags set).
+//
+// At most as many bytes as the current value of the PARAM_BYTES_MAX
+// parameter permits will be read, which is also the most the Magic
+// library would ever look at. The consumed prefix is returned
+// alongside the result so that the caller can continue using the
+// stream without losing any data, for example, by combining both
+// using io.MultiReader.
+func (mgc *Magic) Reader(r io.Reader) (string, []byte, error) {
+	n, err := mgc.Parameter(PARAM_BYTES_MAX)
+	if err != nil {
+		return "", nil, err
+	}
+
+	buffer, err := readPrefix(r, n)
+	if err != nil {
+		return "", buffer, err
+	}
+
+	s, err := mgc.Buffer(buffer)
+	if err != nil {
+		return "", buffer, err
+	}
+	return s, buffer, nil
+}
+
+// Sniff identifies the data at the beginning of the stream and returns
+// a structured result together with a reader that yields the complete
+// original stream, that is the consumed prefix followed by the remainder
+// of the data not yet read, so that the stream can be passed on untouched.
+//
+// The amount of data consumed from the stream is bound by the current
+// value of the PARAM_BYTES_MAX parameter, as per Reader.
+func Sniff(mgc *Magic, r io.Reader) (Result, io.Reader, error) {
+	n, err := mgc.Parameter(PARAM_BYTES_MAX)
+	if err != nil {
+		return Result{}, r, err
+	}
+
+	prefix, err := readPrefix(r, n)
+	if err != nil {
+		return Result{}, replay(prefix, r), err
+	}
+
+	result, err := mgc.BufferResult(prefix)
+	return result, replay(prefix, r), err
+}
+
+// replay returns a reader that yields the prefix followed by the
+// remainder of the stream.
+func replay(prefix []byte, r io.Reader) io.Reader {
+	if len(prefix) == 0 {
+		return r
+	}
+	return io.MultiReader(bytes.NewReader(prefix), r)
+}
+
+// readPrefix reads up to n bytes from the reader, returning
+// whatever was read so far should an error occur.
+func readPrefix(r io.Reader, n int) ([]byte, error) {
+	return ioutil.ReadAll(io.LimitReader(r, int64(n)))
+}
+
+// Sniff identifies the data at the beginning of the stream, as per
+// the Sniff function.
+//
+// The Magic object is taken from the pool only once the prefix of the
+// stream has been read, thus a slow stream does not keep it from being
+// used by other calls in the meantime.
+func (p *Pool) Sniff(r io.Reader) (Result, io.Reader, error) {
+	var n int
+
+	err := p.do(func(mgc *Magic) (err error) {
+		n, err = mgc.Parameter(PARAM_BYTES_MAX)
+		return
+	})
+	if err != nil {
+		return Result{}, r, err
+	}
+
+	prefix, err := readPrefix(r, n)
+	if err != nil {
+		return Result{}, replay(prefix, r), err
+	}
+
+	result, err := p.BufferResult(prefix)
+	return result, replay(prefix, r), err
+}
@@ -0,0 +1,151 @@
+package magic
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestMagic_Reader(t *testing.T) {
+	mgc, err := New()
+	if err != nil {
+		t.Fatalf("unable to create new Magic type: %s", err.Error())
+	}
+	defer mgc.Close()
+
+	if err := mgc.SetFlags(MIME_TYPE); err != nil {
+		t.Fatalf("unable to set flags: %s", err.Error())
+	}
+
+	f, err := os.Open(sampleImageFile)
+	if err != nil {
+		t.Fatalf("unable to open file %q: %s", sampleImageFile, err.Error())
+	}
+	defer f.Close()
+
+	rv, prefix, err := mgc.Reader(f)
+	if err != nil {
+		t.Fatalf("value given {%v}, want {%v}", err, nil)
+	}
+
+	v := "image/png"
+	if ok := compareStrings(rv, v); !ok {
+		t.Errorf("value given %q, want %q", rv, v)
+	}
+
+	expected, err := ioutil.ReadFile(sampleImageFile)
+	if err != nil {
+		t.Fatalf("unable to read file %q: %s", sampleImageFile, err.Error())
+	}
+
+	// Nothing should be lost when the consumed prefix
+	// is put back in front of the remainder of the stream.
+	actual, err := ioutil.ReadAll(io.MultiReader(bytes.NewReader(prefix), f))
+	if err != nil {
+		t.Fatalf("unable to read stream: %s", err.Error())
+	}
+	if !bytes.Equal(actual, expected) {
+		t.Errorf("value given %d bytes, want %d bytes", len(actual), len(expected))
+	}
+}
+
+func TestMagic_Reader_Limit(t *testing.T) {
+	mgc, err := New(WithParameter(PARAM_BYTES_MAX, 16))
+	if err != nil {
+		t.Fatalf("unable to create new Magic type: %s", err.Error())
+	}
+	defer mgc.Close()
+
+	r := strings.NewReader(strings.Repeat("Hello, World!\n", 8))
+
+	_, prefix, err := mgc.Reader(r)
+	if err != nil {
+		t.Fatalf("value given {%v}, want {%v}", err, nil)
+	}
+	if len(prefix) != 16 {
+		t.Errorf("value given %d, want %d", len(prefix), 16)
+	}
+	if r.Len() != 8*14-16 {
+		t.Errorf("value given %d, want %d", r.Len(), 8*14-16)
+	}
+}
+
+func TestMagic_Reader_Closed(t *testing.T) {
+	mgc, err := New()
+	if err != nil {
+		t.Fatalf("unable to create new Magic type: %s", err.Error())
+	}
+	mgc.Close()
+
+	_, _, err = mgc.Reader(strings.NewReader(""))
+
+	v := "magic: Magic library is not open"
+	if err == nil || !compareStrings(err.Error(), v) {
+		t.Errorf("value given {%v}, want {%q}", err, v)
+	}
+}
+
+func TestSniff(t *testing.T) {
+	mgc, err := New(WithFlags(MIME_TYPE), WithParameter(PARAM_BYTES_MAX, 64))
+	if err != nil {
+		t.Fatalf("unable to create new Magic type: %s", err.Error())
+	}
+	defer mgc.Close()
+
+	expected, err := ioutil.ReadFile(sampleImageFile)
+	if err != nil {
+		t.Fatalf("unable to read file %q: %s", sampleImageFile, err.Error())
+	}
+
+	result, r, err := Sniff(mgc, bytes.NewReader(expected))
+	if err != nil {
+		t.Fatalf("value given {%v}, want {%v}", err, nil)
+	}
+
+	rv, v := result.MIMEType, "image/png"
+	if ok := compareStrings(rv, v); !ok {
+		t.Errorf("value given %q, want %q", rv, v)
+	}
+
+	actual, err := ioutil.ReadAll(r)
+	if err != nil {
+		t.Fatalf("unable to read stream: %s", err.Error())
+	}
+	if !bytes.Equal(actual, expected) {
+		t.Errorf("value given %d bytes, want %d bytes", len(actual), len(expected))
+	}
+}
+
+func TestPool_Sniff(t *testing.T) {
+	p, err := NewPool(2, WithParameter(PARAM_BYTES_MAX, 64))
+	if err != nil {
+		t.Fatalf("unable to create new Pool type: %s", err.Error())
+	}
+	defer p.Close()
+
+	expected, err := ioutil.ReadFile(sampleImageFile)
+	if err != nil {
+		t.Fatalf("unable to read file %q: %s", sampleImageFile, err.Error())
+	}
+
+	result, r, err := p.Sniff(bytes.NewReader(expected))
+	if err != nil {
+		t.Fatalf("value given {%v}, want {%v}", err, nil)
+	}
+
+	rv, v := result.MIMEType, "image/png"
+	if ok := compareStrings(rv, v); !ok {
+		t.Errorf("value given %q, want %q", rv, v)
+	}
+
+	actual, err := ioutil.ReadAll(r)
+	if err != nil {
+		t.Fatalf("unable to read stream: %s", err.Error())
+	}
+	if !bytes.Equal(actual, expected) {
+		t.Errorf("value given %d bytes, want %d bytes", len(actual), len(expected))
+	}
+}
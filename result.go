@@ -0,0 +1,275 @@
+package magic
+
+/*
+#include "functions.h"
+*/
+import "C"
+
+import (
+	"mime"
+	"strings"
+	"syscall"
+	"unsafe"
+)
+
+// Flags that select what kind of result the Magic library returns.
+const resultFlags = MIME | EXTENSION | APPLE | CONTINUE
+
+// Result represents a structured result of an identification, as
+// assembled from the results of querying the Magic library with
+// the MIME_TYPE, MIME_ENCODING, EXTENSION, APPLE and CONTINUE flags.
+type Result struct {
+	Description  string            // Textual description of the first match.
+	MIMEType     string            // The MIME type, e.g., "image/png".
+	Parameters   map[string]string // The MIME parameters, e.g., "charset".
+	Extensions   []string          // List of file extensions, if any.
+	AppleCreator string            // The Apple creator, if any.
+	AppleType    string            // The Apple type, if any.
+	Matches      []string          // Textual description of all matches.
+}
+
+// Encoding returns the MIME encoding (the "charset" parameter),
+// or an empty string if there isn't one.
+func (r Result) Encoding() string {
+	return r.Parameters["charset"]
+}
+
+// FileResult returns a structured result for the named file.
+//
+// The Magic library is queried a number of times, once for each
+// kind of result, while the lock is held, thus the result is
+// consistent even when the flags are being changed concurrently.
+// The flags currently set that select the kind of result returned
+// e.g., MIME_TYPE, EXTENSION, etc., are ignored.
+func (mgc *Magic) FileResult(file string) (Result, error) {
+	cFile := C.CString(file)
+	defer C.free(unsafe.Pointer(cFile))
+
+	return mgc.result(func(flags, output C.int) (*C.char, error) {
+		return C.magic_file_wrapper(mgc.cookie, cFile, flags, output), nil
+	})
+}
+
+// BufferResult returns a structured result for the content
+// of the buffer, as per FileResult.
+func (mgc *Magic) BufferResult(buffer []byte) (Result, error) {
+	var p unsafe.Pointer
+
+	cSize := C.size_t(len(buffer))
+	if cSize > 0 {
+		p = unsafe.Pointer(&buffer[0])
+	}
+
+	return mgc.result(func(flags, output C.int) (*C.char, error) {
+		return C.magic_buffer_wrapper(mgc.cookie, p, cSize, flags, output), nil
+	})
+}
+
+// DescriptorResult returns a structured result for the content
+// of the file descriptor, as per FileResult.
+//
+// The file descriptor has to refer to a file that can be read
+// more than once, since the Magic library reads it for each kind
+// of result separately, restoring the file offset each time.
+func (mgc *Magic) DescriptorResult(fd uintptr) (Result, error) {
+	return mgc.result(func(flags, output C.int) (*C.char, error) {
+		cString, err := C.magic_descriptor_wrapper(mgc.cookie, C.int(fd), flags, output)
+		if cString == nil && err != nil {
+			if errno := err.(syscall.Errno); errno == syscall.EBADF {
+				return nil, ErrBadDescriptor
+			}
+		}
+		return cString, nil
+	})
+}
+
+// resultKinds lists each kind of result, with the flag that selects it,
+// whether an error querying for it is fatal, and the function setting it.
+var resultKinds = []struct {
+	flag  Flags
+	fatal bool
+	set   func(*Result, string) error
+}{
+	{CONTINUE, true, func(r *Result, s string) error {
+		if s == "" {
+			return ErrEmptyResult
+		}
+		r.Matches = strings.Split(s, Separator)
+		r.Description = r.Matches[0]
+		return nil
+	}},
+	{MIME, true, func(r *Result, s string) error {
+		r.MIMEType, r.Parameters = parseMIME(s)
+		return nil
+	}},
+	// Neither the file extensions nor the Apple creator and type
+	// are available for every kind of file, such as a directory,
+	// thus any errors are not considered fatal here.
+	{EXTENSION, false, func(r *Result, s string) error {
+		r.Extensions = parseExtensions(s)
+		return nil
+	}},
+	{APPLE, false, func(r *Result, s string) error {
+		r.AppleCreator, r.AppleType = parseApple(s)
+		return nil
+	}},
+}
+
+// resultQueryFlags returns the flags to query the Magic library with for
+// each kind of result, without any of the flags that select the kind, and
+// the lock has to be held by the caller.
+func resultQueryFlags(mgc *Magic) Flags {
+	flags := mgc.flags&^resultFlags | RAW
+	// Make sure to set the "ERROR" flag so that any
+	// I/O-related errors will become first class
+	// errors reported back by the Magic library.
+	if mgc.errors {
+		flags |= ERROR
+	}
+	return flags
+}
+
+// resultString returns the result of a single query, or an error should
+// there be no result, as per the flags the query was made with.
+func resultString(flags Flags, cString, cError *C.char, err func() error) (string, error) {
+	if cString != nil {
+		return C.GoString(cString), nil
+	}
+	if flags&ERROR == 0 && cError != nil {
+		// Report the error message as the result,
+		// as per the File function.
+		return C.GoString(cError), nil
+	}
+	return "", err()
+}
+
+// result assembles a structured result by calling the function once
+// for each kind of result with the appropriate flags set.
+func (mgc *Magic) result(f func(C.int, C.int) (*C.char, error)) (Result, error) {
+	mgc.Lock()
+	defer mgc.Unlock()
+
+	var r Result
+
+	if err := verifyOpen(mgc); err != nil {
+		return r, err
+	}
+	if err := verifyLoaded(mgc); err != nil {
+		return r, err
+	}
+
+	flags := resultQueryFlags(mgc)
+	defer C.magic_setflags_wrapper(mgc.cookie, C.int(mgc.flags))
+
+	query := func(flags Flags) (string, error) {
+		C.magic_setflags_wrapper(mgc.cookie, C.int(flags))
+
+		var (
+			cString *C.char
+			err     error
+		)
+
+		mgc.diagnose(func(output C.int) {
+			cString, err = f(C.int(flags), output)
+		})
+		if err != nil {
+			return "", err
+		}
+
+		var cError *C.char
+		if cString == nil {
+			cError = C.magic_error_wrapper(mgc.cookie)
+		}
+		return resultString(flags, cString, cError, mgc.error)
+	}
+
+	for _, kind := range resultKinds {
+		s, err := query(flags | kind.flag)
+		if err == nil {
+			err = kind.set(&r, s)
+		}
+		if err != nil && kind.fatal {
+			return r, err
+		}
+	}
+	return r, nil
+}
+
+// FileResult returns a structured result for the named file,
+// as per the FileResult function of the Magic type.
+func (p *Pool) FileResult(file string) (r Result, err error) {
+	err = p.do(func(mgc *Magic) (err error) {
+		r, err = mgc.FileResult(file)
+		return
+	})
+	return
+}
+
+// BufferResult returns a structured result for the content
+// of the buffer, as per the BufferResult function of the
+// Magic type.
+func (p *Pool) BufferResult(buffer []byte) (r Result, err error) {
+	err = p.do(func(mgc *Magic) (err error) {
+		r, err = mgc.BufferResult(buffer)
+		return
+	})
+	return
+}
+
+// DescriptorResult returns a structured result for the content
+// of the file descriptor, as per the DescriptorResult function
+// of the Magic type.
+func (p *Pool) DescriptorResult(fd uintptr) (r Result, err error) {
+	err = p.do(func(mgc *Magic) (err error) {
+		r, err = mgc.DescriptorResult(fd)
+		return
+	})
+	return
+}
+
+// parseMIME splits the MIME identification e.g., "image/png;
+// charset=binary", into the MIME type and its parameters.
+func parseMIME(s string) (string, map[string]string) {
+	mediaType, parameters, err := mime.ParseMediaType(s)
+	if err == nil {
+		return mediaType, parameters
+	}
+
+	// Fallback for results that do not strictly conform to
+	// RFC 1521, keeping only the parameters that are valid.
+	parameters = make(map[string]string)
+	fields := strings.Split(s, ";")
+	for _, field := range fields[1:] {
+		kv := strings.SplitN(strings.TrimSpace(field), "=", 2)
+		if len(kv) == 2 && kv[0] != "" {
+			parameters[strings.ToLower(kv[0])] = kv[1]
+		}
+	}
+	return strings.ToLower(strings.TrimSpace(fields[0])), parameters
+}
+
+// parseExtensions splits the slash-separated list of file extensions.
+func parseExtensions(s string) []string {
+	// The "???" string denotes that there are no known
+	// file extensions for a given type of file.
+	if s == "" || s == "???" {
+		return nil
+	}
+	return strings.Split(s, "/")
+}
+
+// parseApple splits the Apple creator and type e.g., "8BIMJPEG".
+func parseApple(s string) (string, string) {
+	if len(s) != 8 {
+		return "", ""
+	}
+	creator, kind := s[:4], s[4:]
+	// The "UNKN" string denotes that the value is not known.
+	if creator == "UNKN" {
+		creator = ""
+	}
+	if kind == "UNKN" {
+		kind = ""
+	}
+	return creator, kind
+}
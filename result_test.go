@@ -0,0 +1,144 @@
+package magic
+
+import (
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestMagic_FileResult(t *testing.T) {
+	mgc, err := New(WithFlags(MIME_TYPE | EXTENSION))
+	if err != nil {
+		t.Fatalf("unable to create new Magic type: %s", err.Error())
+	}
+	defer mgc.Close()
+
+	rv, err := mgc.FileResult(sampleImageFile)
+	if err != nil {
+		t.Fatalf("value given {%v}, want {%v}", err, nil)
+	}
+
+	v := "PNG image data, 1634 x 2224, 8-bit/color RGBA, non-interlaced"
+	if ok := compareStrings(rv.Description, v); !ok {
+		t.Errorf("value given %q, want %q", rv.Description, v)
+	}
+	if ok := compareStrings(rv.Matches[0], v); !ok {
+		t.Errorf("value given %q, want %q", rv.Matches[0], v)
+	}
+	if v := "image/png"; !compareStrings(rv.MIMEType, v) {
+		t.Errorf("value given %q, want %q", rv.MIMEType, v)
+	}
+	if v := "binary"; !compareStrings(rv.Encoding(), v) {
+		t.Errorf("value given %q, want %q", rv.Encoding(), v)
+	}
+	if v := []string{"png"}; !reflect.DeepEqual(rv.Extensions, v) {
+		t.Errorf("value given %v, want %v", rv.Extensions, v)
+	}
+
+	// Flags currently set should remain unchanged.
+	if flags, _ := mgc.Flags(); flags != MIME_TYPE|EXTENSION {
+		t.Errorf("value given 0x%06x, want 0x%06x", flags, MIME_TYPE|EXTENSION)
+	}
+
+	_, err = mgc.FileResult("does/not/exist")
+	if err == nil {
+		t.Errorf("value given {%v}, want an error", err)
+	}
+}
+
+func TestMagic_BufferResult(t *testing.T) {
+	mgc, err := New()
+	if err != nil {
+		t.Fatalf("unable to create new Magic type: %s", err.Error())
+	}
+	defer mgc.Close()
+
+	rv, err := mgc.BufferResult([]byte("#!/bin/bash\n\n"))
+	if err != nil {
+		t.Fatalf("value given {%v}, want {%v}", err, nil)
+	}
+
+	if v := "text/x-shellscript"; !compareStrings(rv.MIMEType, v) {
+		t.Errorf("value given %q, want %q", rv.MIMEType, v)
+	}
+	if v := "us-ascii"; !compareStrings(rv.Encoding(), v) {
+		t.Errorf("value given %q, want %q", rv.Encoding(), v)
+	}
+	if !strings.Contains(rv.Description, "shell script") {
+		t.Errorf("value given %q, want a shell script", rv.Description)
+	}
+	if rv.Extensions != nil {
+		t.Errorf("value given %v, want %v", rv.Extensions, nil)
+	}
+}
+
+func TestMagic_DescriptorResult(t *testing.T) {
+	mgc, err := New()
+	if err != nil {
+		t.Fatalf("unable to create new Magic type: %s", err.Error())
+	}
+	defer mgc.Close()
+
+	f, err := os.Open(sampleImageFile)
+	if err != nil {
+		t.Fatalf("unable to open file %q: %s", sampleImageFile, err.Error())
+	}
+	defer f.Close()
+
+	rv, err := mgc.DescriptorResult(f.Fd())
+	if err != nil {
+		t.Fatalf("value given {%v}, want {%v}", err, nil)
+	}
+	if v := "image/png"; !compareStrings(rv.MIMEType, v) {
+		t.Errorf("value given %q, want %q", rv.MIMEType, v)
+	}
+
+	_, err = mgc.DescriptorResult(uintptr(1 << 16))
+
+	v := "magic: bad file descriptor"
+	if err == nil || !compareStrings(err.Error(), v) {
+		t.Errorf("value given {%v}, want {%q}", err, v)
+	}
+}
+
+func TestParseResult(t *testing.T) {
+	var parseTests = []struct {
+		given      string
+		mediaType  string
+		parameters map[string]string
+	}{
+		{
+			"image/png; charset=binary",
+			"image/png",
+			map[string]string{"charset": "binary"},
+		},
+		{
+			"text/plain",
+			"text/plain",
+			map[string]string{},
+		},
+		{
+			"application/octet-stream; charset=binary; broken",
+			"application/octet-stream",
+			map[string]string{"charset": "binary"},
+		},
+	}
+
+	for _, tt := range parseTests {
+		mediaType, parameters := parseMIME(tt.given)
+		if mediaType != tt.mediaType || !reflect.DeepEqual(parameters, tt.parameters) {
+			t.Errorf("value given %q %v, want %q %v", mediaType, parameters, tt.mediaType, tt.parameters)
+		}
+	}
+
+	if v := parseExtensions("jpeg/jpg/jpe/jfif"); !reflect.DeepEqual(v, []string{"jpeg", "jpg", "jpe", "jfif"}) {
+		t.Errorf("value given %v, want %v", v, []string{"jpeg", "jpg", "jpe", "jfif"})
+	}
+	if creator, kind := parseApple("8BIMJPEG"); creator != "8BIM" || kind != "JPEG" {
+		t.Errorf("value given %q %q, want %q %q", creator, kind, "8BIM", "JPEG")
+	}
+	if creator, kind := parseApple("UNKNUNKN"); creator != "" || kind != "" {
+		t.Errorf("value given %q %q, want empty", creator, kind)
+	}
+}
@@ -0,0 +1,9 @@
+/*
+Package rule implements a builder for the Magic rules that renders these
+as source Magic files, as described in the magic(5) manual page, taking
+care of the continuation levels, escaping of the tests, and directives.
+
+The source Magic file rendered can be compiled using the CompileBuffers
+function of the magic package, and then loaded using LoadBuffers.
+*/
+package rule
@@ -0,0 +1,41 @@
+package rule_test
+
+import (
+	"fmt"
+
+	"github.com/kwilczynski/go-magic"
+	"github.com/kwilczynski/go-magic/rule"
+)
+
+// This example shows how to define a Magic rule, compile it, and then
+// load it, to identify a file using nothing but the Magic rule defined.
+func Example() {
+	png := rule.At(0).String("\x89PNG\r\n\x1a\n").Message("PNG image data").MIME("image/png").Child(
+		rule.At(16).BELong().Format(", %d x").NoSpace(),
+		rule.At(20).BELong().Format("%d"),
+	)
+
+	source, err := rule.Render(png)
+	if err != nil {
+		panic(fmt.Sprintf("Unable to render Magic rule: %s\n", err))
+	}
+
+	compiled, err := magic.CompileBuffers(source)
+	if err != nil {
+		panic(fmt.Sprintf("Unable to compile Magic rule: %s\n", err))
+	}
+
+	m, err := magic.New(magic.WithBuffers(compiled))
+	if err != nil {
+		panic(fmt.Sprintf("An has error occurred: %s\n", err))
+	}
+	defer m.Close()
+
+	description, err := m.File("../test/fixtures/gopher.png")
+	if err != nil {
+		panic(fmt.Sprintf("Unable to determine file type: %s\n", err))
+	}
+	fmt.Printf("File type is: %s\n", description)
+	// Output:
+	// File type is: PNG image data, 1634 x 2224
+}
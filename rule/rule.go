@@ -0,0 +1,345 @@
+package rule
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Rule represents a single Magic rule (an entry), together with its
+// directives and continuation rules.
+type Rule struct {
+	offset   string
+	kind     string
+	flags    string
+	mask     string
+	test     string
+	message  string
+	noSpace  bool
+	mime     string
+	ext      string
+	apple    string
+	strength string
+	children []*Rule
+	err      error
+}
+
+// At returns a new rule that applies at the given offset.
+func At(offset int64) *Rule {
+	return &Rule{offset: strconv.FormatInt(offset, 10)}
+}
+
+// AtOffset returns a new rule that applies at the given offset, written
+// as per the magic(5) manual page, e.g., "&0" for an offset relative to
+// the end of the previous match, or "(4.l+4)" for an indirect offset.
+func AtOffset(offset string) *Rule {
+	r := &Rule{offset: offset}
+	if offset == "" || strings.ContainsAny(offset, " \t\r\n") {
+		r.setError("invalid offset %q", offset)
+	}
+	return r
+}
+
+// Type sets the type of the rule, written as per the magic(5) manual
+// page, e.g., "lemsdosdate".
+func (r *Rule) Type(kind string) *Rule {
+	if kind == "" || strings.ContainsAny(kind, " \t\r\n") {
+		r.setError("invalid type %q", kind)
+	}
+	r.kind = kind
+	return r
+}
+
+// Byte sets the type of the rule to a one-byte value.
+func (r *Rule) Byte() *Rule { return r.Type("byte") }
+
+// Short sets the type of the rule to a two-byte value in the byte order
+// of the machine.
+func (r *Rule) Short() *Rule { return r.Type("short") }
+
+// BEShort sets the type of the rule to a two-byte big-endian value.
+func (r *Rule) BEShort() *Rule { return r.Type("beshort") }
+
+// LEShort sets the type of the rule to a two-byte little-endian value.
+func (r *Rule) LEShort() *Rule { return r.Type("leshort") }
+
+// Long sets the type of the rule to a four-byte value in the byte order
+// of the machine.
+func (r *Rule) Long() *Rule { return r.Type("long") }
+
+// BELong sets the type of the rule to a four-byte big-endian value.
+func (r *Rule) BELong() *Rule { return r.Type("belong") }
+
+// LELong sets the type of the rule to a four-byte little-endian value.
+func (r *Rule) LELong() *Rule { return r.Type("lelong") }
+
+// Quad sets the type of the rule to an eight-byte value in the byte order
+// of the machine.
+func (r *Rule) Quad() *Rule { return r.Type("quad") }
+
+// BEQuad sets the type of the rule to an eight-byte big-endian value.
+func (r *Rule) BEQuad() *Rule { return r.Type("bequad") }
+
+// LEQuad sets the type of the rule to an eight-byte little-endian value.
+func (r *Rule) LEQuad() *Rule { return r.Type("lequad") }
+
+// String sets the type of the rule to a string, and the test to match
+// the string exactly, escaping any characters as necessary.
+func (r *Rule) String(s string) *Rule {
+	r.Type("string")
+	r.test = escape(s)
+	return r
+}
+
+// Search sets the type of the rule to a search for the string within
+// the given number of bytes from the offset.
+func (r *Rule) Search(s string, n int) *Rule {
+	if n < 1 {
+		r.setError("invalid search range %d", n)
+	}
+	r.Type("search/" + strconv.Itoa(n))
+	r.test = escape(s)
+	return r
+}
+
+// Flags sets the flags of the string type, e.g., "w" to treat any
+// whitespace as optional, or "c" for case-insensitive match.
+func (r *Rule) Flags(flags string) *Rule {
+	if strings.ContainsAny(flags, " \t\r\n/") {
+		r.setError("invalid flags %q", flags)
+	}
+	r.flags = flags
+	return r
+}
+
+// Mask sets a mask that the value is AND-ed with before the test.
+func (r *Rule) Mask(mask uint64) *Rule {
+	r.mask = "&0x" + strconv.FormatUint(mask, 16)
+	return r
+}
+
+// Any sets the test of the rule to match any value, which is
+// the default for the numeric types.
+func (r *Rule) Any() *Rule { return r.Test("x") }
+
+// Equal sets the test of the rule to match the value.
+func (r *Rule) Equal(v int64) *Rule { return r.Test(strconv.FormatInt(v, 10)) }
+
+// NotEqual sets the test of the rule to match any other value.
+func (r *Rule) NotEqual(v int64) *Rule { return r.Test("!" + strconv.FormatInt(v, 10)) }
+
+// Less sets the test of the rule to match values less than the value.
+func (r *Rule) Less(v int64) *Rule { return r.Test("<" + strconv.FormatInt(v, 10)) }
+
+// Greater sets the test of the rule to match values greater than the value.
+func (r *Rule) Greater(v int64) *Rule { return r.Test(">" + strconv.FormatInt(v, 10)) }
+
+// Test sets the test of the rule, written as per the magic(5) manual
+// page, without any escaping.
+func (r *Rule) Test(test string) *Rule {
+	if test == "" || strings.ContainsAny(test, "\r\n") {
+		r.setError("invalid test %q", test)
+	}
+	r.test = test
+	return r
+}
+
+// Message sets the message of the rule, which is printed as is. Any
+// "%" characters are escaped, so that these are not treated as part
+// of a format.
+func (r *Rule) Message(s string) *Rule {
+	return r.Format(strings.Replace(s, "%", "%%", -1))
+}
+
+// Format sets the message of the rule, which can contain a format, such
+// as "%d", that the value matched is formatted with.
+func (r *Rule) Format(s string) *Rule {
+	if strings.ContainsAny(s, "\r\n") {
+		r.setError("invalid message %q", s)
+	}
+	r.message = s
+	return r
+}
+
+// NoSpace sets the message of the rule to be printed without a space
+// separating it from the message that precedes it, which is otherwise
+// added by the Magic library.
+func (r *Rule) NoSpace() *Rule {
+	r.noSpace = true
+	return r
+}
+
+// MIME sets the MIME type, e.g., "image/png".
+func (r *Rule) MIME(mimeType string) *Rule {
+	if !strings.Contains(mimeType, "/") || strings.ContainsAny(mimeType, " \t\r\n") {
+		r.setError("invalid MIME type %q", mimeType)
+	}
+	r.mime = mimeType
+	return r
+}
+
+// Ext sets the list of file extensions, e.g., "jpeg" and "jpg".
+func (r *Rule) Ext(extensions ...string) *Rule {
+	for _, ext := range extensions {
+		if ext == "" || strings.ContainsAny(ext, " \t\r\n/") {
+			r.setError("invalid extension %q", ext)
+		}
+	}
+	r.ext = strings.Join(extensions, "/")
+	return r
+}
+
+// Apple sets the Apple creator and type, e.g., "8BIM" and "PNGf".
+func (r *Rule) Apple(creator, kind string) *Rule {
+	if len(creator) != 4 || len(kind) != 4 {
+		r.setError("invalid Apple creator and type %q", creator+kind)
+	}
+	r.apple = creator + kind
+	return r
+}
+
+// Strength changes the strength of the rule, which is used to order the
+// rules, using the operator, one of "+", "-", "*" or "/", and the value.
+func (r *Rule) Strength(op byte, v int) *Rule {
+	if !strings.ContainsRune("+-*/", rune(op)) || v < 0 {
+		r.setError("invalid strength %c%d", op, v)
+	}
+	r.strength = fmt.Sprintf("%c%d", op, v)
+	return r
+}
+
+// Child adds the rules as continuation rules, which are only tested
+// should this rule match.
+func (r *Rule) Child(children ...*Rule) *Rule {
+	r.children = append(r.children, children...)
+	return r
+}
+
+// Err returns the first error of the rule, or of any of its continuation
+// rules, if any, or nil otherwise.
+func (r *Rule) Err() error {
+	if r.err != nil {
+		return r.err
+	}
+	if r.kind == "" {
+		return r.error("missing type")
+	}
+	if r.test == "" && !isNumeric(r.kind) {
+		return r.error("missing test")
+	}
+	for _, c := range r.children {
+		if err := c.Err(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Render renders the rules as a source Magic file.
+func Render(rules ...*Rule) ([]byte, error) {
+	var buffer bytes.Buffer
+	for _, r := range rules {
+		if err := r.Err(); err != nil {
+			return nil, err
+		}
+		r.render(&buffer, 0)
+	}
+	return buffer.Bytes(), nil
+}
+
+func (r *Rule) render(buffer *bytes.Buffer, level int) {
+	test := r.test
+	if test == "" {
+		test = "x"
+	}
+
+	buffer.WriteString(strings.Repeat(">", level))
+	buffer.WriteString(r.offset)
+	buffer.WriteByte('\t')
+	buffer.WriteString(r.kind)
+	if r.flags != "" {
+		buffer.WriteString("/" + r.flags)
+	}
+	buffer.WriteString(r.mask)
+	buffer.WriteByte('\t')
+	buffer.WriteString(test)
+	if r.message != "" || r.noSpace {
+		buffer.WriteByte('\t')
+		if r.noSpace {
+			buffer.WriteString(`\b`)
+		}
+		buffer.WriteString(r.message)
+	}
+	buffer.WriteByte('\n')
+
+	for _, d := range []struct{ name, value string }{
+		{"mime", r.mime},
+		{"ext", r.ext},
+		{"apple", r.apple},
+		{"strength", r.strength},
+	} {
+		if d.value != "" {
+			buffer.WriteString("!:" + d.name + "\t" + d.value + "\n")
+		}
+	}
+
+	for _, c := range r.children {
+		c.render(buffer, level+1)
+	}
+}
+
+func (r *Rule) setError(format string, args ...interface{}) {
+	if r.err == nil {
+		r.err = r.error(fmt.Sprintf(format, args...))
+	}
+}
+
+func (r *Rule) error(s string) error {
+	return errors.New("rule: at offset " + r.offset + ": " + s)
+}
+
+// isNumeric returns true if the type is numeric, or false otherwise.
+func isNumeric(kind string) bool {
+	switch strings.TrimPrefix(kind, "u") {
+	case "byte", "short", "beshort", "leshort",
+		"long", "belong", "lelong",
+		"quad", "bequad", "lequad":
+		return true
+	}
+	return false
+}
+
+// escape escapes the string so that it can be used as the test.
+func escape(s string) string {
+	// A lone "x" would otherwise match any value.
+	if s == "x" {
+		return `\x78`
+	}
+
+	var buffer bytes.Buffer
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case c == '\\':
+			buffer.WriteString(`\\`)
+		case c == ' ':
+			buffer.WriteString(`\ `)
+		case c == '\t':
+			buffer.WriteString(`\t`)
+		case c == '\r':
+			buffer.WriteString(`\r`)
+		case c == '\n':
+			buffer.WriteString(`\n`)
+		case i == 0 && strings.IndexByte("<>=!&^~", c) >= 0:
+			// These would otherwise be treated as an operator.
+			fmt.Fprintf(&buffer, `\x%02x`, c)
+		case c < 0x20 || c >= 0x7f:
+			fmt.Fprintf(&buffer, `\x%02x`, c)
+		default:
+			buffer.WriteByte(c)
+		}
+	}
+	return buffer.String()
+}
@@ -0,0 +1,122 @@
+package rule
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/kwilczynski/go-magic"
+)
+
+func TestRender(t *testing.T) {
+	png := At(0).String("\x89PNG\r\n\x1a\n").Message("PNG image data").MIME("image/png").Ext("png").Child(
+		At(16).BELong().Format(", %d x").NoSpace(),
+		At(20).BELong().Format("%d,"),
+		At(25).Byte().Equal(6).Message("100% RGBA").Child(
+			AtOffset("&0").Byte().Mask(0xff).Greater(1),
+		),
+	)
+	shell := At(0).String("#! /bin/sh").Flags("wt").Message("POSIX shell script").Strength('/', 2)
+
+	b, err := Render(png, shell)
+	if err != nil {
+		t.Fatalf("value given {%v}, want {%v}", err, nil)
+	}
+
+	v := strings.Join([]string{
+		`0	string	\x89PNG\r\n\x1a\n	PNG image data`,
+		`!:mime	image/png`,
+		`!:ext	png`,
+		`>16	belong	x	\b, %d x`,
+		`>20	belong	x	%d,`,
+		`>25	byte	6	100%% RGBA`,
+		`>>&0	byte&0xff	>1`,
+		`0	string/wt	#!\ /bin/sh	POSIX shell script`,
+		`!:strength	/2`,
+		``,
+	}, "\n")
+	if string(b) != v {
+		t.Errorf("value given %q, want %q", b, v)
+	}
+}
+
+func TestRender_Escape(t *testing.T) {
+	var escapeTests = []struct {
+		given    string
+		expected string
+	}{
+		{"PNG", "PNG"},
+		{`C:\ `, `C:\\\ `},
+		{"\t\x00\xff", `\t\x00\xff`},
+		{"<?xml", `\x3c?xml`},
+		{"a<b", "a<b"},
+		{"x", `\x78`},
+		{"xx", "xx"},
+	}
+
+	for _, tt := range escapeTests {
+		if v := escape(tt.given); v != tt.expected {
+			t.Errorf("value given %q, want %q", v, tt.expected)
+		}
+	}
+}
+
+func TestRender_Match(t *testing.T) {
+	var matchTests = []struct {
+		rule    *Rule
+		given   string
+		matched bool
+	}{
+		{At(0).String("x").Message("matched"), "xylophone", true},
+		{At(0).String("x").Message("matched"), "yodel", false},
+		{At(0).String("x").Message("matched"), "\x00\x01\x02\x03", false},
+		{At(0).String("<?x").Message("matched"), "<?xml", true},
+		{At(0).String("<?x").Message("matched"), "=?xml", false},
+	}
+
+	for _, tt := range matchTests {
+		source, err := Render(tt.rule)
+		if err != nil {
+			t.Fatalf("value given {%v}, want {%v}", err, nil)
+		}
+		compiled, err := magic.CompileBuffers(source)
+		if err != nil {
+			t.Fatalf("unable to compile Magic rule: %s", err.Error())
+		}
+		mgc, err := magic.New(magic.WithBuffers(compiled))
+		if err != nil {
+			t.Fatalf("unable to create new Magic type: %s", err.Error())
+		}
+
+		rv, err := mgc.Buffer([]byte(tt.given))
+		if err != nil {
+			t.Errorf("value given {%v}, want {%v}", err, nil)
+		}
+		if matched := rv == "matched"; matched != tt.matched {
+			t.Errorf("value given %q, want a match %v for %q", rv, tt.matched, tt.given)
+		}
+		mgc.Close()
+	}
+}
+
+func TestRender_Errors(t *testing.T) {
+	var errorTests = []struct {
+		given    *Rule
+		expected string
+	}{
+		{At(0), "rule: at offset 0: missing type"},
+		{At(0).Type("regex"), "rule: at offset 0: missing test"},
+		{At(0).Byte().Format("one\ntwo"), `rule: at offset 0: invalid message "one\ntwo"`},
+		{At(0).Byte().MIME("png"), `rule: at offset 0: invalid MIME type "png"`},
+		{At(0).Byte().Apple("8BIM", "PNG"), `rule: at offset 0: invalid Apple creator and type "8BIMPNG"`},
+		{At(0).Byte().Strength('%', 2), "rule: at offset 0: invalid strength %2"},
+		{At(0).Byte().Child(At(4).Search("", 0)), "rule: at offset 4: invalid search range 0"},
+		{AtOffset("(4 .l)").Byte(), `rule: at offset (4 .l): invalid offset "(4 .l)"`},
+	}
+
+	for _, tt := range errorTests {
+		_, err := Render(tt.given)
+		if err == nil || err.Error() != tt.expected {
+			t.Errorf("value given {%v}, want {%q}", err, tt.expected)
+		}
+	}
+}
@@ -0,0 +1,142 @@
+package magic
+
+import (
+	"mime"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// Conformity represents whether the extension of a file name conforms
+// to the content of the file.
+type Conformity int
+
+const (
+	// There is not enough evidence to tell either way e.g., the file
+	// name has no extension, or nothing is known about the content.
+	ConformityUnknown Conformity = iota
+
+	// The extension is one of the extensions known for the content.
+	ConformityMatch
+
+	// The extension is not one of the extensions known for the content.
+	ConformityMismatch
+)
+
+// String returns a string representation of the Conformity type.
+func (c Conformity) String() string {
+	switch c {
+	case ConformityUnknown:
+		return "unknown"
+	case ConformityMatch:
+		return "match"
+	case ConformityMismatch:
+		return "mismatch"
+	}
+	return "Conformity(" + strconv.Itoa(int(c)) + ")"
+}
+
+// Verdict represents the outcome of comparing the extension of a file
+// name against the content of the file, together with the evidence.
+type Verdict struct {
+	Conformity Conformity // Whether the extension conforms to the content.
+	Extension  string     // The extension, in lower case, without the dot.
+	Result     Result     // The structured result of the identification.
+	// List of extensions for the MIME type detected, without the dot,
+	// as per the ExtensionsByType function of the mime package.
+	MIMEExtensions []string
+	// The MIME type for the extension, as per the TypeByExtension
+	// function of the mime package, if any, without any parameters.
+	ExtensionType string
+}
+
+// VerifyExtension compares the extension of the named file against both
+// the list of extensions that the Magic library reports for the content
+// of the file, as per the EXTENSION flag, and the extensions registered
+// for the MIME type detected, as per the mime package.
+//
+// The extension matches if it is in either of the lists, or if the MIME
+// type registered for the extension is the MIME type detected, and does
+// not otherwise, unless there is no evidence to compare it against, in
+// which case the outcome is unknown. Content that is only identified as
+// "application/octet-stream", thus as data, always has unknown outcome.
+//
+// Extensions are compared without regard to case.
+func (mgc *Magic) VerifyExtension(path string) (Verdict, error) {
+	r, err := mgc.FileResult(path)
+	if err != nil {
+		return Verdict{}, err
+	}
+	return verify(path, r), nil
+}
+
+// VerifyExtension compares the extension of the named file against the
+// content of the file, as per the VerifyExtension function of the Magic
+// type.
+func VerifyExtension(path string, options ...Option) (Verdict, error) {
+	mgc, err := New(options...)
+	if err != nil {
+		return Verdict{}, err
+	}
+	defer mgc.Close()
+	return mgc.VerifyExtension(path)
+}
+
+// VerifyExtension compares the extension of the named file against the
+// content of the file, as per the VerifyExtension function of the Magic
+// type.
+func (p *Pool) VerifyExtension(path string) (v Verdict, err error) {
+	err = p.do(func(mgc *Magic) (err error) {
+		v, err = mgc.VerifyExtension(path)
+		return
+	})
+	return
+}
+
+// verify compares the extension of the file name against the result.
+func verify(path string, r Result) Verdict {
+	v := Verdict{
+		Extension: strings.ToLower(strings.TrimPrefix(filepath.Ext(path), ".")),
+		Result:    r,
+	}
+
+	if r.MIMEType != "" {
+		extensions, _ := mime.ExtensionsByType(r.MIMEType)
+		for _, e := range extensions {
+			v.MIMEExtensions = append(v.MIMEExtensions, strings.TrimPrefix(e, "."))
+		}
+	}
+	if v.Extension == "" {
+		return v
+	}
+	if t := mime.TypeByExtension("." + v.Extension); t != "" {
+		v.ExtensionType, _, _ = mime.ParseMediaType(t)
+	}
+
+	if r.MIMEType == "" || r.MIMEType == defaultContentType {
+		return v
+	}
+	if containsFold(r.Extensions, v.Extension) || containsFold(v.MIMEExtensions, v.Extension) {
+		v.Conformity = ConformityMatch
+		return v
+	}
+	if v.ExtensionType != "" && strings.EqualFold(v.ExtensionType, r.MIMEType) {
+		v.Conformity = ConformityMatch
+		return v
+	}
+	if len(r.Extensions) > 0 || len(v.MIMEExtensions) > 0 || v.ExtensionType != "" {
+		v.Conformity = ConformityMismatch
+	}
+	return v
+}
+
+// containsFold returns true if the string is in the list, without
+// regard to case.
+func containsFold(list []string, s string) bool {
+	for _, e := range list {
+		if strings.EqualFold(e, s) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,122 @@
+package magic
+
+import (
+	"io/ioutil"
+	"path"
+	"reflect"
+	"testing"
+)
+
+func TestMagic_VerifyExtension(t *testing.T) {
+	mgc, err := New()
+	if err != nil {
+		t.Fatalf("unable to create new Magic type: %s", err.Error())
+	}
+	defer mgc.Close()
+
+	image, err := ioutil.ReadFile(sampleImageFile)
+	if err != nil {
+		t.Fatalf("unable to read file %q: %s", sampleImageFile, err.Error())
+	}
+
+	dir := t.TempDir()
+
+	var verifyTests = []struct {
+		name       string
+		conformity Conformity
+	}{
+		{"gopher.png", ConformityMatch},
+		{"GOPHER.PNG", ConformityMatch},
+		{"invoice.pdf", ConformityMismatch},
+		{"gopher.jpg", ConformityMismatch},
+		{"gopher", ConformityUnknown},
+	}
+
+	for _, tt := range verifyTests {
+		file := path.Join(dir, tt.name)
+		if err := ioutil.WriteFile(file, image, 0644); err != nil {
+			t.Fatalf("unable to write file %q: %s", file, err.Error())
+		}
+
+		v, err := mgc.VerifyExtension(file)
+		if err != nil {
+			t.Fatalf("value given {%v}, want {%v}", err, nil)
+		}
+		if v.Conformity != tt.conformity {
+			t.Errorf("value given %s, want %s for %q", v.Conformity, tt.conformity, tt.name)
+		}
+		if rv := v.Result.MIMEType; !compareStrings(rv, "image/png") {
+			t.Errorf("value given %q, want %q", rv, "image/png")
+		}
+	}
+
+	_, err = mgc.VerifyExtension("does/not/exist.png")
+	if err == nil {
+		t.Errorf("value given {%v}, want an error", err)
+	}
+}
+
+func TestVerifyExtension(t *testing.T) {
+	v, err := VerifyExtension(sampleImageFile)
+	if err != nil {
+		t.Fatalf("value given {%v}, want {%v}", err, nil)
+	}
+	if v.Conformity != ConformityMatch {
+		t.Errorf("value given %s, want %s", v.Conformity, ConformityMatch)
+	}
+	if v := v.Extension; !compareStrings(v, "png") {
+		t.Errorf("value given %q, want %q", v, "png")
+	}
+}
+
+func TestVerify(t *testing.T) {
+	var verifyTests = []struct {
+		path       string
+		result     Result
+		conformity Conformity
+		extension  string
+		fileType   string
+	}{
+		{"invoice.pdf", Result{MIMEType: "application/x-executable"}, ConformityMismatch, "pdf", "application/pdf"},
+		{"invoice.pdf", Result{MIMEType: "application/pdf", Extensions: []string{"pdf"}}, ConformityMatch, "pdf", "application/pdf"},
+		{"invoice.PDF", Result{MIMEType: "application/pdf"}, ConformityMatch, "pdf", "application/pdf"},
+		{"archive.xyz", Result{MIMEType: "application/x-xyz"}, ConformityUnknown, "xyz", ""},
+		{"archive.xyz", Result{MIMEType: "application/x-xyz", Extensions: []string{"zyx"}}, ConformityMismatch, "xyz", ""},
+		{"data.pdf", Result{MIMEType: "application/octet-stream"}, ConformityUnknown, "pdf", "application/pdf"},
+		{"README", Result{MIMEType: "text/plain"}, ConformityUnknown, "", ""},
+	}
+
+	for _, tt := range verifyTests {
+		v := verify(tt.path, tt.result)
+		if v.Conformity != tt.conformity {
+			t.Errorf("value given %s, want %s for %q", v.Conformity, tt.conformity, tt.path)
+		}
+		if v.Extension != tt.extension {
+			t.Errorf("value given %q, want %q", v.Extension, tt.extension)
+		}
+		if v.ExtensionType != tt.fileType {
+			t.Errorf("value given %q, want %q", v.ExtensionType, tt.fileType)
+		}
+		if !reflect.DeepEqual(v.Result, tt.result) {
+			t.Errorf("value given %+v, want %+v", v.Result, tt.result)
+		}
+	}
+}
+
+func TestConformity_String(t *testing.T) {
+	var conformityTests = []struct {
+		given    Conformity
+		expected string
+	}{
+		{ConformityUnknown, "unknown"},
+		{ConformityMatch, "match"},
+		{ConformityMismatch, "mismatch"},
+		{Conformity(42), "Conformity(42)"},
+	}
+
+	for _, tt := range conformityTests {
+		if v := tt.given.String(); !compareStrings(v, tt.expected) {
+			t.Errorf("value given %q, want %q", v, tt.expected)
+		}
+	}
+}
@@ -0,0 +1,177 @@
+package magic
+
+import (
+	"context"
+	"io/fs"
+	"path/filepath"
+	"runtime"
+	"sync"
+)
+
+// WalkOptions represents options that control the Walk function.
+type WalkOptions struct {
+	// Context that stops the walk once done, if any.
+	Context context.Context
+	// Number of files identified concurrently, each using its own
+	// Magic object, defaults to the number of CPUs.
+	Workers int
+	// Options used to create each of the Magic objects, which are
+	// the same as the ones that New accepts.
+	Options []Option
+	// Patterns, as per filepath.Match, that the name of a file or
+	// its path relative to the root has to match, if any.
+	Include []string
+	// Patterns, as per filepath.Match, that exclude any file or
+	// directory that either its name or relative path matches.
+	Exclude []string
+}
+
+// WalkFunc is the type of the function called by Walk for each file.
+//
+// The error is either the error encountered when walking the tree of
+// files, or when identifying the file. Should the function return an
+// error, then the walk stops, and Walk returns the error.
+type WalkFunc func(path string, r Result, err error) error
+
+// walkResult represents the result for a single file.
+type walkResult struct {
+	path   string
+	result Result
+	err    error
+}
+
+// Walk walks the tree of files rooted at root, and calls the function
+// with a structured result, as per FileResult, for each file, including
+// symbolic links and special files, that the include and exclude patterns
+// permit. Directories are only walked, and are not identified.
+//
+// Files are identified concurrently using a pool of the Magic objects,
+// and the function is called serially, in the order in which files were
+// identified, which can differ from the lexical order. The flags set using
+// the options, such as SYMLINK or DEVICES, apply as they would otherwise,
+// but symbolic links to directories are never followed.
+//
+// The walk stops as soon as the function returns an error, or the context
+// is done, in which case the error of the context is returned.
+func Walk(root string, opts WalkOptions, fn WalkFunc) error {
+	for _, pattern := range append(opts.Include, opts.Exclude...) {
+		if _, err := filepath.Match(pattern, ""); err != nil {
+			return err
+		}
+	}
+
+	parent := opts.Context
+	if parent == nil {
+		parent = context.Background()
+	}
+	workers := opts.Workers
+	if workers < 1 {
+		workers = runtime.NumCPU()
+	}
+
+	p, err := NewPool(workers, opts.Options...)
+	if err != nil {
+		return err
+	}
+	defer p.Close()
+
+	ctx, cancel := context.WithCancel(parent)
+	defer cancel()
+
+	var (
+		wg      sync.WaitGroup
+		paths   = make(chan string)
+		results = make(chan walkResult)
+	)
+
+	send := func(r walkResult) bool {
+		select {
+		case results <- r:
+			return true
+		case <-ctx.Done():
+			return false
+		}
+	}
+
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for path := range paths {
+				r, err := p.FileResult(path)
+				if !send(walkResult{path, r, err}) {
+					return
+				}
+			}
+		}()
+	}
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		defer close(paths)
+
+		filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				if !send(walkResult{path: path, err: err}) {
+					return ctx.Err()
+				}
+				return nil
+			}
+			if path != root && matchAny(opts.Exclude, root, path) {
+				if d.IsDir() {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+			if d.IsDir() || len(opts.Include) > 0 && !matchAny(opts.Include, root, path) {
+				return nil
+			}
+			select {
+			case paths <- path:
+				return nil
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		})
+	}()
+
+	go func() {
+		wg.Wait()
+		close(results)
+	}()
+
+	for r := range results {
+		err := parent.Err()
+		if err == nil {
+			err = fn(r.path, r.result, r.err)
+		}
+		if err != nil {
+			cancel()
+			// Wait for the workers to finish.
+			for range results {
+			}
+			return err
+		}
+	}
+	return parent.Err()
+}
+
+// matchAny returns true if either the name or the path, relative to
+// the root, matches any of the patterns, or false otherwise.
+func matchAny(patterns []string, root, path string) bool {
+	rel, err := filepath.Rel(root, path)
+	if err != nil {
+		rel = path
+	}
+	name := filepath.Base(path)
+	for _, pattern := range patterns {
+		if ok, _ := filepath.Match(pattern, name); ok {
+			return true
+		}
+		if ok, _ := filepath.Match(pattern, rel); ok {
+			return true
+		}
+	}
+	return false
+}
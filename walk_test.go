@@ -0,0 +1,111 @@
+package magic
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path"
+	"reflect"
+	"testing"
+)
+
+func TestWalk(t *testing.T) {
+	var walkTests = []struct {
+		options  WalkOptions
+		expected map[string]string
+	}{
+		{
+			WalkOptions{Workers: 2, Include: []string{"*.png", "*.jpg"}},
+			map[string]string{
+				path.Join(fixturesDirectory, "gopher.jpg"): "image/jpeg",
+				path.Join(fixturesDirectory, "gopher.png"): "image/png",
+			},
+		},
+		{
+			WalkOptions{Exclude: []string{"*.magic", "gopher.jpg"}},
+			map[string]string{
+				path.Join(fixturesDirectory, "gopher.png"): "image/png",
+			},
+		},
+		{
+			WalkOptions{Workers: 1, Include: []string{"fixtures/gopher.png"}},
+			map[string]string{
+				path.Join(fixturesDirectory, "gopher.png"): "image/png",
+			},
+		},
+		{
+			WalkOptions{Exclude: []string{"fixtures"}},
+			map[string]string{},
+		},
+	}
+
+	for _, tt := range walkTests {
+		v := make(map[string]string)
+		err := Walk(testDirectory, tt.options, func(path string, r Result, err error) error {
+			if err != nil {
+				return err
+			}
+			v[path] = r.MIMEType
+			return nil
+		})
+		if err != nil {
+			t.Fatalf("value given {%v}, want {%v}", err, nil)
+		}
+		if !reflect.DeepEqual(v, tt.expected) {
+			t.Errorf("value given %v, want %v", v, tt.expected)
+		}
+	}
+}
+
+func TestWalk_Stop(t *testing.T) {
+	errStop := errors.New("stop")
+
+	var paths []string
+	err := Walk(fixturesDirectory, WalkOptions{Workers: 2}, func(path string, r Result, err error) error {
+		paths = append(paths, path)
+		return errStop
+	})
+	if err != errStop {
+		t.Errorf("value given {%v}, want {%v}", err, errStop)
+	}
+	if len(paths) != 1 {
+		t.Errorf("value given %v, want a single path", paths)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	err = Walk(fixturesDirectory, WalkOptions{Context: ctx}, func(path string, r Result, err error) error {
+		t.Errorf("value given %q, want none", path)
+		return nil
+	})
+	if err != context.Canceled {
+		t.Errorf("value given {%v}, want {%v}", err, context.Canceled)
+	}
+}
+
+func TestWalk_Errors(t *testing.T) {
+	var errs []string
+	err := Walk("does/not/exist", WalkOptions{}, func(path string, r Result, err error) error {
+		if errors.Is(err, os.ErrNotExist) {
+			errs = append(errs, path)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("value given {%v}, want {%v}", err, nil)
+	}
+	if v := []string{"does/not/exist"}; !reflect.DeepEqual(errs, v) {
+		t.Errorf("value given %v, want %v", errs, v)
+	}
+
+	err = Walk(fixturesDirectory, WalkOptions{Include: []string{"["}}, nil)
+	if err == nil {
+		t.Errorf("value given {%v}, want an error", err)
+	}
+
+	err = Walk(fixturesDirectory, WalkOptions{Options: []Option{WithFiles("does/not/exist")}}, nil)
+	if err == nil {
+		t.Errorf("value given {%v}, want an error", err)
+	}
+}